@@ -0,0 +1,79 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter spaces events at least the interval apart.
+type Limiter struct {
+	interval time.Duration
+	next     time.Time
+	nextMu   sync.Mutex
+}
+
+func NewLimiter(interval time.Duration) *Limiter {
+	return &Limiter{
+		interval: interval,
+	}
+}
+
+// Wait blocks until the next event is allowed or the context is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.nextMu.Lock()
+
+	now := time.Now()
+	at := l.next
+
+	if at.Before(now) {
+		at = now
+	}
+
+	l.next = at.Add(l.interval)
+	l.nextMu.Unlock()
+
+	if delay := at.Sub(now); delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return nil
+}
+
+// Allow takes the next event if it's allowed at now without waiting,
+// otherwise it returns false and the time the next event is allowed.
+func (l *Limiter) Allow(now time.Time) (time.Time, bool) {
+	l.nextMu.Lock()
+	defer l.nextMu.Unlock()
+
+	if l.next.After(now) {
+		return l.next, false
+	}
+
+	l.next = now.Add(l.interval)
+
+	return time.Time{}, true
+}
+
+// Delay postpones all following events by d, e.g. when the remote side asks
+// to slow down.
+func (l *Limiter) Delay(d time.Duration) {
+	l.nextMu.Lock()
+	defer l.nextMu.Unlock()
+
+	if next := time.Now().Add(d); next.After(l.next) {
+		l.next = next
+	}
+}
+
+// Idle reports whether no event is scheduled after now.
+func (l *Limiter) Idle(now time.Time) bool {
+	l.nextMu.Lock()
+	defer l.nextMu.Unlock()
+
+	return !l.next.After(now)
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/discovery"
+	"github.com/dyptan-io/rtx-sniper-bot/history"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+const (
+	importMerge   = "merge"
+	importReplace = "replace"
+)
+
+type (
+	// backupHeader is the first line of an export with the versions of the
+	// stores, exports without it hold version 0 of all stores.
+	backupHeader struct {
+		Versions map[string]int `json:"versions"`
+	}
+
+	// backupRecord is a line of an export, which holds one stored item.
+	backupRecord struct {
+		Store   string          `json:"store"`
+		Key     string          `json:"key"`
+		Value   json.RawMessage `json:"value"`
+		Expires *time.Time      `json:"expires,omitempty"`
+	}
+
+	// backupStore exports and imports the items of a store.
+	backupStore interface {
+		// version is the version of the exported items.
+		version() int
+		records() ([]backupRecord, error)
+		// apply migrates the records from the version of the export.
+		apply(records []backupRecord, version int, replace, dryRun bool, diff io.Writer) (importStats, error)
+	}
+
+	storeBackup[T any] struct {
+		name       string
+		store      storage.Store[T]
+		migrations storage.Option
+	}
+
+	importStats struct {
+		added, updated, removed int
+	}
+)
+
+// backupStores returns the stores in the order of the export.
+func backupStores(st *stores) ([]string, map[string]backupStore) {
+	names := []string{"requests", "history", "outbox", "skus"}
+
+	return names, map[string]backupStore{
+		"requests": storeBackup[monitor.Request]{"requests", st.requests, storage.WithMigrations(monitor.RequestMigrations...)},
+		"history":  storeBackup[history.Record]{"history", st.history, storage.WithKeyMigrations(history.Migrations...)},
+		"outbox":   storeBackup[monitor.Notification]{"outbox", st.outbox, storage.WithMigrations()},
+		"skus":     storeBackup[discovery.SKU]{"skus", st.skus, storage.WithMigrations()},
+	}
+}
+
+// runExport writes the versions of the stores and all stored items as JSON
+// lines ordered by store and key.
+func runExport(log *slog.Logger, cfg *config, args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "", "write to the file instead of stdout")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sniper export [-o file]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	st, err := openStores(cfg)
+	if err != nil {
+		log.Error("Failed to initialize storage.", "error", err)
+		return 1
+	}
+	defer st.Close()
+
+	var w io.Writer = os.Stdout
+
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Error("Failed to create export file.", "error", err)
+			return 1
+		}
+
+		defer f.Close()
+
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	names, backups := backupStores(st)
+	header := backupHeader{Versions: make(map[string]int)}
+
+	for _, name := range names {
+		header.Versions[name] = backups[name].version()
+	}
+
+	if err := enc.Encode(header); err != nil {
+		log.Error("Failed to write export.", "error", err)
+		return 1
+	}
+
+	for _, name := range names {
+		records, err := backups[name].records()
+		if err != nil {
+			log.Error("Failed to export store.", "store", name, "error", err)
+			return 1
+		}
+
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				log.Error("Failed to write export.", "error", err)
+				return 1
+			}
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		log.Error("Failed to write export.", "error", err)
+		return 1
+	}
+
+	return 0
+}
+
+// runImport restores an export, migrating it from the versions of its header,
+// merging it into the stored items or replacing them, and prints the changes.
+func runImport(log *slog.Logger, cfg *config, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	mode := fs.String("mode", importMerge, "merge into the stored items or replace them")
+	dryRun := fs.Bool("dry-run", false, "print the changes without applying them")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sniper import [-mode merge|replace] [-dry-run] [file]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *mode != importMerge && *mode != importReplace || fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	var r io.Reader = os.Stdin
+
+	if fs.NArg() == 1 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			log.Error("Failed to open import file.", "error", err)
+			return 1
+		}
+
+		defer f.Close()
+
+		r = f
+	}
+
+	header, records, err := readBackup(r)
+	if err != nil {
+		log.Error("Failed to read import.", "error", err)
+		return 1
+	}
+
+	st, err := openStores(cfg)
+	if err != nil {
+		log.Error("Failed to initialize storage.", "error", err)
+		return 1
+	}
+	defer st.Close()
+
+	names, backups := backupStores(st)
+
+	for store := range records {
+		if _, ok := backups[store]; !ok {
+			log.Error("Failed to import.", "error", fmt.Sprintf("unknown store %q", store))
+			return 1
+		}
+	}
+
+	// Check all stores first, so a newer export isn't imported partially.
+	for store, version := range header.Versions {
+		if b, ok := backups[store]; ok && version > b.version() {
+			log.Error("Failed to import.", "error", fmt.Sprintf("%s has version %d, newer than the supported version %d", store, version, b.version()))
+			return 1
+		}
+	}
+
+	for _, name := range names {
+		stats, err := backups[name].apply(records[name], header.Versions[name], *mode == importReplace, *dryRun, os.Stdout)
+		if err != nil {
+			log.Error("Failed to import store.", "store", name, "error", err)
+			return 1
+		}
+
+		log.Info("Imported store.", "store", name, "added", stats.added, "updated", stats.updated, "removed", stats.removed, "dryRun", *dryRun)
+	}
+
+	return 0
+}
+
+// readBackup reads the header and the records by store of an export.
+func readBackup(r io.Reader) (backupHeader, map[string][]backupRecord, error) {
+	var (
+		header  backupHeader
+		records = make(map[string][]backupRecord)
+		dec     = json.NewDecoder(r)
+	)
+
+	for line := 1; ; line++ {
+		var raw json.RawMessage
+
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			return header, records, nil
+		} else if err != nil {
+			return backupHeader{}, nil, fmt.Errorf("record %d: %w", line, err)
+		}
+
+		var rec backupRecord
+
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return backupHeader{}, nil, fmt.Errorf("record %d: %w", line, err)
+		}
+
+		if line == 1 && rec.Store == "" {
+			if err := json.Unmarshal(raw, &header); err == nil && header.Versions != nil {
+				continue
+			}
+		}
+
+		if rec.Store == "" || rec.Key == "" {
+			return backupHeader{}, nil, fmt.Errorf("record %d: missing store or key", line)
+		}
+
+		records[rec.Store] = append(records[rec.Store], rec)
+	}
+}
+
+func (b storeBackup[T]) version() int {
+	return storage.Version(b.migrations)
+}
+
+func (b storeBackup[T]) records() ([]backupRecord, error) {
+	items := maps.Collect(b.store.All())
+	records := make([]backupRecord, 0, len(items))
+
+	for _, key := range slices.Sorted(maps.Keys(items)) {
+		value, err := json.Marshal(items[key])
+		if err != nil {
+			return nil, err
+		}
+
+		rec := backupRecord{
+			Store: b.name,
+			Key:   key,
+			Value: value,
+		}
+
+		if expires, ok := b.store.Expiry(key); ok {
+			rec.Expires = &expires
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// apply writes the records to the store and the changes to diff, prefixed
+// with "+" for added, "~" for updated and "-" for removed items. Imported items
+// get the expiry of their record, or none. Stores implementing
+// storage.Batcher write all changes at once.
+func (b storeBackup[T]) apply(records []backupRecord, version int, replace, dryRun bool, diff io.Writer) (importStats, error) {
+	var (
+		stats    importStats
+		existing = maps.Collect(b.store.All())
+		imported = make(map[string]T)
+		expires  = make(map[string]time.Time)
+		keys     []string
+		removed  []string
+	)
+
+	for _, rec := range records {
+		items, err := storage.Migrate(version, rec.Key, rec.Value, b.migrations)
+		if err != nil {
+			return stats, err
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(items)) {
+			var item T
+
+			if err := json.Unmarshal(items[key], &item); err != nil {
+				return stats, fmt.Errorf("decoding %q: %w", key, err)
+			}
+
+			if _, ok := imported[key]; !ok {
+				keys = append(keys, key)
+			}
+
+			imported[key] = item
+
+			if rec.Expires != nil {
+				expires[key] = *rec.Expires
+			} else {
+				delete(expires, key)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		item, at := imported[key], expires[key]
+		old, ok := existing[key]
+		oldAt, _ := b.store.Expiry(key)
+
+		switch {
+		case !ok:
+			stats.added++
+			fmt.Fprintf(diff, "+ %s/%s %s\n", b.name, key, formatItem(item, at))
+		case !equalJSON(old, item) || !oldAt.Equal(at):
+			stats.updated++
+			fmt.Fprintf(diff, "~ %s/%s\n  - %s\n  + %s\n", b.name, key, formatItem(old, oldAt), formatItem(item, at))
+		}
+	}
+
+	if replace {
+		for _, key := range slices.Sorted(maps.Keys(existing)) {
+			if _, ok := imported[key]; ok {
+				continue
+			}
+
+			at, _ := b.store.Expiry(key)
+			removed = append(removed, key)
+			stats.removed++
+			fmt.Fprintf(diff, "- %s/%s %s\n", b.name, key, formatItem(existing[key], at))
+		}
+	}
+
+	if dryRun {
+		return stats, nil
+	}
+
+	write := func(fn func() error) error { return fn() }
+	if batcher, ok := b.store.(storage.Batcher); ok {
+		write = batcher.Batch
+	}
+
+	return stats, write(func() error {
+		for _, key := range keys {
+			if err := b.store.Add(key, imported[key]); err != nil {
+				return err
+			}
+
+			// The zero time clears the expiry of a replaced item.
+			if err := b.store.SetExpiry(key, expires[key]); err != nil {
+				return err
+			}
+		}
+
+		for _, key := range removed {
+			if err := b.store.Remove(key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// formatItem returns the item as JSON followed by its expiry, if any.
+func formatItem(item any, expires time.Time) string {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+
+	if expires.IsZero() {
+		return string(data)
+	}
+
+	return fmt.Sprintf("%s (expires %s)", data, expires.Format(time.RFC3339))
+}
+
+func equalJSON(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/history"
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+func testConfig(t *testing.T) *config {
+	dir := t.TempDir()
+
+	return &config{
+		StorageFile: filepath.Join(dir, "db.json"),
+		HistoryFile: filepath.Join(dir, "history.json"),
+		OutboxFile:  filepath.Join(dir, "outbox.json"),
+		SKUsFile:    filepath.Join(dir, "skus.json"),
+	}
+}
+
+func TestImportMigratesExports(t *testing.T) {
+	// Written before the export header, with requests and history of
+	// version 0.
+	const legacy = `{"store":"requests","key":"123","value":{"products":["RTX 5090 FE"],"countries":["Sweden"],"maxPrices":{"RTX 5090 FE":20000}}}
+{"store":"history","key":"1147625","value":[{"sku":"1147625","product":"RTX 5090 FE","country":"Sweden","retailer":"Komplett","stock":3,"time":"2025-01-30T14:00:00Z"},{"sku":"1147625","product":"RTX 5090 FE","country":"Sweden","retailer":"Komplett","time":"2025-01-30T14:05:00Z"}]}
+`
+
+	header, records, err := readBackup(strings.NewReader(legacy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testConfig(t)
+
+	st, err := openStores(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names, backups := backupStores(st)
+
+	for _, name := range names {
+		if _, err := backups[name].apply(records[name], header.Versions[name], false, false, io.Discard); err != nil {
+			t.Fatalf("importing %s: %v", name, err)
+		}
+	}
+
+	req, ok := st.requests.Get("123")
+	if !ok || req.ChatID != 123 || req.MaxPrices["RTX 5090 FE"]["SEK"] != 20000 {
+		t.Errorf("request = %+v, want the migrated request", req)
+	}
+
+	if got := history.New(st.history).SKU("1147625"); len(got) != 2 || !got[1].Time.Equal(time.Date(2025, 1, 30, 14, 5, 0, 0, time.UTC)) {
+		t.Errorf("history = %+v, want a record per transition", got)
+	}
+
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Exports start with the current versions.
+	name := filepath.Join(t.TempDir(), "backup.ndjson")
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	if code := runExport(log, cfg, []string{"-o", name}); code != 0 {
+		t.Fatalf("export exited with %d", code)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, records, err = readBackup(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"versions":{"history":1,"outbox":0,"requests":2,"skus":0}}`; !strings.HasPrefix(string(data), want+"\n") {
+		t.Errorf("export starts with %q, want %q", strings.SplitN(string(data), "\n", 2)[0], want)
+	}
+
+	if len(records["requests"]) != 1 || len(records["history"]) != 2 {
+		t.Errorf("exported %d requests and %d history records, want 1 and 2", len(records["requests"]), len(records["history"]))
+	}
+
+	// The export imports unchanged.
+	if code := runImport(log, testConfig(t), []string{name}); code != 0 {
+		t.Errorf("import exited with %d", code)
+	}
+}
+
+func TestImportRefusesNewerExports(t *testing.T) {
+	cfg := testConfig(t)
+
+	export := `{"versions":{"requests":99}}
+{"store":"requests","key":"123","value":{"products":["RTX 5090 FE"],"countries":["Sweden"]}}
+`
+	name := filepath.Join(t.TempDir(), "backup.ndjson")
+
+	if err := os.WriteFile(name, []byte(export), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	if code := runImport(log, cfg, []string{name}); code != 1 {
+		t.Errorf("import exited with %d, want 1", code)
+	}
+
+	st, err := openStores(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	if _, ok := st.requests.Get("123"); ok {
+		t.Error("imported a request of a newer version")
+	}
+}
+
+func TestImportApply(t *testing.T) {
+	expires := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	records := []backupRecord{
+		{Store: "test", Key: "a", Value: json.RawMessage(`{"v":"1"}`)},
+		{Store: "test", Key: "b", Value: json.RawMessage(`{"v":"2"}`), Expires: &expires},
+		{Store: "test", Key: "d", Value: json.RawMessage(`{"v":"1"}`)},
+	}
+
+	tests := []struct {
+		name    string
+		replace bool
+		dryRun  bool
+		want    importStats
+		keys    []string
+	}{
+		{name: "merge", want: importStats{added: 1, updated: 2}, keys: []string{"a", "b", "c", "d"}},
+		{name: "replace", replace: true, want: importStats{added: 1, updated: 2, removed: 1}, keys: []string{"a", "b", "d"}},
+		{name: "dry run", replace: true, dryRun: true, want: importStats{added: 1, updated: 2, removed: 1}, keys: []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.New[map[string]string]()
+
+			for _, key := range []string{"a", "b", "c"} {
+				if err := store.Add(key, map[string]string{"v": "1"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, key := range []string{"a", "b"} {
+				if err := store.SetExpiry(key, expires); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var diff strings.Builder
+
+			b := storeBackup[map[string]string]{"test", store, storage.WithMigrations()}
+
+			stats, err := b.apply(records, 0, tt.replace, tt.dryRun, &diff)
+			if err != nil {
+				t.Fatalf("apply() error = %v", err)
+			}
+
+			if stats != tt.want {
+				t.Errorf("apply() = %+v, want %+v", stats, tt.want)
+			}
+
+			want := `~ test/a
+  - {"v":"1"} (expires 2025-03-01T12:00:00Z)
+  + {"v":"1"}
+~ test/b
+  - {"v":"1"} (expires 2025-03-01T12:00:00Z)
+  + {"v":"2"} (expires 2025-03-01T12:00:00Z)
++ test/d {"v":"1"}
+`
+			if tt.replace {
+				want += "- test/c {\"v\":\"1\"}\n"
+			}
+
+			if diff.String() != want {
+				t.Errorf("diff =\n%s\nwant\n%s", diff.String(), want)
+			}
+
+			if got := slices.Sorted(maps.Keys(maps.Collect(store.All()))); !slices.Equal(got, tt.keys) {
+				t.Errorf("keys = %v, want %v", got, tt.keys)
+			}
+
+			// The imported record has no expiry, the old one is cleared.
+			if _, ok := store.Expiry("a"); ok != tt.dryRun {
+				t.Errorf("a has expiry %t, want %t", ok, tt.dryRun)
+			}
+		})
+	}
+}
@@ -0,0 +1,979 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/big"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/history"
+	"github.com/dyptan-io/rtx-sniper-bot/i18n"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/notify"
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+type (
+	// bot handles the Telegram conversations in private chats, groups and
+	// channels.
+	bot struct {
+		api        *tgbotapi.BotAPI
+		mon        *monitor.Monitor
+		hist       *history.Store
+		templates  *notify.Templates
+		notifiers  map[string]notify.Notifier
+		email      *notify.Email
+		products   []string
+		countries  []string
+		catalogMu  sync.RWMutex
+		selections map[int64]userSelection
+		// emailSent holds the times of the last verification emails by chat
+		// and by address.
+		emailSent map[string]time.Time
+		// admins caches the administrator status of group members.
+		admins map[chatMember]adminStatus
+		log    *slog.Logger
+	}
+
+	chatMember struct {
+		ChatID int64
+		UserID int
+	}
+
+	adminStatus struct {
+		Admin   bool
+		Checked time.Time
+	}
+
+	userSelection struct {
+		Products  []string
+		Countries []string
+	}
+)
+
+// newBot creates the bot with the notifiers of the additional channels, email
+// may be nil if SMTP is not configured.
+func newBot(log *slog.Logger, api *tgbotapi.BotAPI, mon *monitor.Monitor, hist *history.Store, templates *notify.Templates, notifiers map[string]notify.Notifier, email *notify.Email) *bot {
+	b := &bot{
+		api:       api,
+		mon:       mon,
+		hist:      hist,
+		templates: templates,
+		notifiers: notifiers,
+		email:     email,
+		products: []string{
+			string(nvidia.ProductRTX5080),
+			string(nvidia.ProductRTX5090),
+		},
+		countries:  make([]string, 0, len(nvidia.Countries())),
+		selections: make(map[int64]userSelection),
+		emailSent:  make(map[string]time.Time),
+		admins:     make(map[chatMember]adminStatus),
+		log:        log,
+	}
+
+	for _, c := range nvidia.Countries() {
+		b.countries = append(b.countries, c.String())
+	}
+
+	return b
+}
+
+// addProducts offers the products and countries of further stock sources,
+// e.g. retailer scrapers or discovered SKUs.
+func (b *bot) addProducts(products []nvidia.Product, countries []nvidia.Country) {
+	b.catalogMu.Lock()
+	defer b.catalogMu.Unlock()
+
+	for _, p := range products {
+		if !slices.Contains(b.products, string(p)) {
+			b.products = append(b.products, string(p))
+		}
+	}
+
+	for _, c := range countries {
+		if !slices.Contains(b.countries, string(c)) {
+			b.countries = append(b.countries, string(c))
+		}
+	}
+}
+
+// catalog returns the products and the countries offering any of them.
+func (b *bot) catalog() ([]string, []string) {
+	b.catalogMu.RLock()
+	products, countries := slices.Clone(b.products), slices.Clone(b.countries)
+	b.catalogMu.RUnlock()
+
+	countries = slices.DeleteFunc(countries, func(c string) bool {
+		return !slices.ContainsFunc(products, func(p string) bool {
+			return b.mon.Offers(p, c)
+		})
+	})
+
+	return products, countries
+}
+
+// alertOperators sends the text to the chats of the operators.
+func (b *bot) alertOperators(chatIDs []int64, text string) {
+	for _, chatID := range chatIDs {
+		b.send(tgbotapi.NewMessage(chatID, text))
+	}
+}
+
+// notify sends the notification with a button per retailer offer. Errors are
+// translated for the notify.Dispatcher.
+func (b *bot) notify(_ context.Context, notif monitor.Notification) error {
+	text, err := b.templates.Render(notify.ChannelTelegram, notif)
+	if err != nil {
+		return err
+	}
+
+	buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(notif.Offers))
+
+	for _, o := range notif.Offers {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonURL(o.Retailer, o.Link))
+	}
+
+	msg := tgbotapi.NewMessage(notif.ChatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(buttons...),
+	)
+
+	_, err = b.api.Send(msg)
+
+	return dispatchError(err)
+}
+
+// dispatchError translates errors of the Telegram API for the
+// notify.Dispatcher.
+func dispatchError(err error) error {
+	var apiErr tgbotapi.Error
+
+	switch {
+	case !errors.As(err, &apiErr):
+		return err
+	case apiErr.RetryAfter > 0:
+		return &notify.RetryError{After: time.Duration(apiErr.RetryAfter) * time.Second, Err: err}
+	case isChatGone(apiErr.Message):
+		return fmt.Errorf("%w: %w", notify.ErrChatGone, err)
+	default:
+		return err
+	}
+}
+
+func (b *bot) handle(message *tgbotapi.Message) {
+	var (
+		chatID    = message.Chat.ID
+		key       = strconv.FormatInt(chatID, 10)
+		text      = message.Text
+		cmd, arg  = cutCommand(text)
+		lang      = b.language(message)
+		reply     = func(msg string) { b.send(tgbotapi.NewMessage(chatID, msg)) }
+		sel, inUI = b.selections[chatID]
+
+		allProducts, allCountries = b.catalog()
+	)
+
+	// In groups only administrators manage the subscription, channel posts
+	// can only be sent by administrators. Other chatter is ignored without
+	// asking Telegram, unless a selection is in progress.
+	if message.Chat.IsGroup() || message.Chat.IsSuperGroup() {
+		if !message.IsCommand() && !inUI {
+			return
+		}
+
+		if !readOnlyCommand(cmd) && !b.isAdmin(message) {
+			if strings.HasPrefix(text, "/") {
+				reply(i18n.T(lang, "admin_only"))
+			}
+
+			return
+		}
+	}
+
+	switch {
+	case cmd == "/start":
+		reply(i18n.T(lang, "welcome"))
+
+	case cmd == "/monitor" && arg == "" && message.Chat.IsChannel():
+		// Channels don't support reply keyboards.
+		reply(i18n.T(lang, "usage_monitor", strings.Join(allProducts, ", "), strings.Join(allCountries, ", ")))
+
+	case cmd == "/monitor" && arg != "":
+		prodQuery, countryQuery, _ := strings.Cut(arg, ";")
+
+		products, okProducts := findAll(allProducts, prodQuery)
+		countries, okCountries := findAll(allCountries, countryQuery)
+
+		if !okProducts || !okCountries {
+			reply(i18n.T(lang, "usage_monitor", strings.Join(allProducts, ", "), strings.Join(allCountries, ", ")))
+			return
+		}
+
+		b.subscribe(message.Chat, lang, products, countries)
+
+	case cmd == "/monitor":
+		b.selections[chatID] = userSelection{}
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "select_products"))
+		msg.ReplyMarkup = selection(allProducts, nil, i18n.T(lang, "confirm_products"))
+		b.send(msg)
+
+	case inUI && text == i18n.T(lang, "confirm_products") && len(sel.Products) > 0:
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "select_countries"))
+		msg.ReplyMarkup = selection(allCountries, nil, i18n.T(lang, "confirm_countries"))
+		b.send(msg)
+
+	case inUI && text == i18n.T(lang, "confirm_countries") && len(sel.Countries) > 0:
+		b.subscribe(message.Chat, lang, sel.Products, sel.Countries)
+		delete(b.selections, chatID)
+
+	case slices.Contains(allProducts, text):
+		// Avoid duplicate selection
+		if !slices.Contains(sel.Products, text) {
+			sel.Products = append(sel.Products, text)
+			b.selections[chatID] = sel
+		}
+
+		// Send updated menu with remaining options
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "selected_product", text))
+		msg.ReplyMarkup = selection(allProducts, sel.Products, i18n.T(lang, "confirm_products"))
+		b.send(msg)
+
+	case slices.Contains(allCountries, text):
+		// Avoid duplicate selection
+		if !slices.Contains(sel.Countries, text) {
+			sel.Countries = append(sel.Countries, text)
+			b.selections[chatID] = sel
+		}
+
+		// Send updated menu with remaining options
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "selected_country", text))
+		msg.ReplyMarkup = selection(allCountries, sel.Countries, i18n.T(lang, "confirm_countries"))
+		b.send(msg)
+
+	case cmd == "/history" || cmd == "/export":
+		prod, ok := findProduct(allProducts, arg)
+		if !ok && (cmd == "/history" || arg != "") {
+			reply(i18n.T(lang, "usage_product", cmd, strings.Join(allProducts, ", ")))
+			return
+		}
+
+		records := b.hist.All()
+		if ok {
+			records = b.hist.Product(prod)
+		}
+
+		if cmd == "/history" {
+			reply(formatHistory(lang, prod, records))
+			return
+		}
+
+		var buf bytes.Buffer
+
+		if err := history.WriteCSV(&buf, records); err != nil {
+			b.log.Error("Failed to export history.", "error", err)
+			return
+		}
+
+		b.send(tgbotapi.NewDocumentUpload(chatID, tgbotapi.FileBytes{
+			Name:  "history.csv",
+			Bytes: buf.Bytes(),
+		}))
+
+	case cmd == "/forecast":
+		prod, ok := findProduct(allProducts, arg)
+		if !ok {
+			reply(i18n.T(lang, "usage_product", cmd, strings.Join(allProducts, ", ")))
+			return
+		}
+
+		reply(formatForecast(lang, b.hist, nvidia.Product(prod), allCountries))
+
+	case cmd == "/retailers" || cmd == "/allow" || cmd == "/deny":
+		if cmd != "/retailers" {
+			retailers := splitList(arg)
+
+			if err := b.mon.Update(key, func(req *monitor.Request) {
+				if cmd == "/allow" {
+					req.Retailers = retailers
+				} else {
+					req.ExcludedRetailers = retailers
+				}
+			}); err != nil {
+				b.log.Error("Failed to update retailer filter.", "chatID", chatID, "error", err)
+				return
+			}
+
+			b.log.Info("Retailer filter updated", "chatID", chatID, "command", cmd, "retailers", retailers)
+		}
+
+		req, _ := b.mon.Subscription(key)
+		reply(formatRetailers(lang, req))
+
+	case cmd == "/maxprice":
+		if arg != "" {
+			req, _ := b.mon.Subscription(key)
+
+			prod, price, currency, ok := parseMaxPrice(allProducts, arg)
+			if ok && price != 0 && currency == "" {
+				// Default to the currency of the monitored countries.
+				if currencies := req.Currencies(); len(currencies) == 1 {
+					currency = currencies[0]
+				}
+			}
+
+			if !ok || price != 0 && currency == "" {
+				reply(i18n.T(lang, "maxprice_usage"))
+				return
+			}
+
+			if err := b.mon.Update(key, func(req *monitor.Request) {
+				switch {
+				case price != 0:
+					if req.MaxPrices == nil {
+						req.MaxPrices = make(map[string]map[string]float64)
+					}
+
+					if req.MaxPrices[prod] == nil {
+						req.MaxPrices[prod] = make(map[string]float64)
+					}
+
+					req.MaxPrices[prod][currency] = float64(price)
+				case currency != "":
+					delete(req.MaxPrices[prod], currency)
+
+					if len(req.MaxPrices[prod]) == 0 {
+						delete(req.MaxPrices, prod)
+					}
+				default:
+					delete(req.MaxPrices, prod)
+				}
+			}); err != nil {
+				b.log.Error("Failed to update max price.", "chatID", chatID, "error", err)
+				return
+			}
+
+			b.log.Info("Max price updated", "chatID", chatID, "product", prod, "price", price, "currency", currency)
+		}
+
+		req, _ := b.mon.Subscription(key)
+		reply(formatMaxPrices(lang, req))
+
+	case cmd == "/language":
+		if !i18n.Supported(arg) {
+			var langs []string
+
+			for _, l := range i18n.Languages() {
+				langs = append(langs, l.String()+" ("+l.Name()+")")
+			}
+
+			reply(i18n.T(lang, "language_usage", strings.Join(langs, ", ")))
+			return
+		}
+
+		lang = i18n.Parse(arg)
+
+		if err := b.mon.Update(key, func(req *monitor.Request) {
+			req.Language = lang.String()
+		}); err != nil {
+			b.log.Error("Failed to update language.", "chatID", chatID, "error", err)
+			return
+		}
+
+		reply(i18n.T(lang, "language_set", lang.Name()))
+
+	case cmd == "/discord" || cmd == "/slack":
+		channel, label := notify.ChannelDiscord, "Discord"
+		if cmd == "/slack" {
+			channel, label = notify.ChannelSlack, "Slack"
+		}
+
+		validator, ok := b.notifiers[channel].(notify.Validator)
+		if arg != "off" && (!ok || validator.Validate(arg) != nil) {
+			reply(i18n.T(lang, "channel_usage", cmd, cmd))
+			return
+		}
+
+		if err := b.mon.Update(key, func(req *monitor.Request) {
+			if arg == "off" {
+				delete(req.Channels, channel)
+				return
+			}
+
+			if req.Channels == nil {
+				req.Channels = make(map[string]string)
+			}
+
+			req.Channels[channel] = arg
+		}); err != nil {
+			b.log.Error("Failed to update notification channel.", "chatID", chatID, "channel", channel, "error", err)
+			return
+		}
+
+		b.log.Info("Notification channel updated", "chatID", chatID, "channel", channel, "enabled", arg != "off")
+
+		if arg == "off" {
+			reply(i18n.T(lang, "channel_disabled", label))
+		} else {
+			reply(i18n.T(lang, "channel_enabled", label))
+		}
+
+	case cmd == "/email":
+		if b.email == nil {
+			reply(i18n.T(lang, "email_unavailable"))
+			return
+		}
+
+		if arg == "off" {
+			if err := b.mon.Update(key, func(req *monitor.Request) {
+				delete(req.Channels, notify.ChannelEmail)
+				clearEmailCode(req)
+			}); err != nil {
+				b.log.Error("Failed to disable email.", "chatID", chatID, "error", err)
+				return
+			}
+
+			reply(i18n.T(lang, "email_disabled"))
+			return
+		}
+
+		addr, err := mail.ParseAddress(arg)
+		if err != nil {
+			reply(i18n.T(lang, "email_usage"))
+			return
+		}
+
+		if !b.allowEmail(time.Now(), "chat:"+key, "address:"+strings.ToLower(addr.Address)) {
+			reply(i18n.T(lang, "email_cooldown"))
+			return
+		}
+
+		code, err := verificationCode()
+		if err != nil {
+			b.log.Error("Failed to generate verification code.", "error", err)
+			return
+		}
+
+		// Slow SMTP servers must not block the other chats.
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			if err := b.email.Send(ctx, addr.Address, i18n.T(lang, "email_verify_subject"), i18n.T(lang, "email_verify_body", code), ""); err != nil {
+				b.log.Error("Failed to send verification email.", "chatID", chatID, "error", err)
+				reply(i18n.T(lang, "email_failed", addr.Address))
+				return
+			}
+
+			expires := time.Now().Add(emailCodeLifetime)
+
+			if err := b.mon.Update(key, func(req *monitor.Request) {
+				req.PendingEmail, req.EmailCode = addr.Address, code
+				req.EmailCodeExpires, req.EmailAttempts = &expires, 0
+			}); err != nil {
+				b.log.Error("Failed to store pending email.", "chatID", chatID, "error", err)
+				return
+			}
+
+			reply(i18n.T(lang, "email_sent", addr.Address))
+		}()
+
+	case cmd == "/verify":
+		if req, _ := b.mon.Subscription(key); req.EmailCode == "" {
+			reply(i18n.T(lang, "email_invalid_code"))
+			return
+		}
+
+		var (
+			result  verifyResult
+			address string
+		)
+
+		if err := b.mon.Update(key, func(req *monitor.Request) {
+			address = req.PendingEmail
+			result = verifyEmailCode(req, arg, time.Now())
+		}); err != nil {
+			b.log.Error("Failed to verify email.", "chatID", chatID, "error", err)
+			return
+		}
+
+		switch result {
+		case codeValid:
+			b.log.Info("Email verified", "chatID", chatID)
+			reply(i18n.T(lang, "email_verified", address))
+		case codeInvalid:
+			reply(i18n.T(lang, "email_invalid_code"))
+		case codeExpired:
+			reply(i18n.T(lang, "email_code_expired"))
+		}
+
+	case cmd == "/renew":
+		expires, ok, err := b.mon.Renew(key)
+		if err != nil {
+			b.log.Error("Failed to renew subscription.", "chatID", chatID, "error", err)
+			return
+		}
+
+		if !ok {
+			reply(i18n.T(lang, "subscription_none"))
+			return
+		}
+
+		reply(i18n.T(lang, "subscription_renewed", expires.Format(time.DateOnly)))
+
+	case cmd == "/unmonitor":
+		b.mon.Unmonitor(key)
+		reply(i18n.T(lang, "monitoring_stopped"))
+
+		b.log.Info("Monitor removed", "chatID", chatID)
+
+	case message.Chat.IsPrivate():
+		reply(i18n.T(lang, "unknown_command"))
+	}
+}
+
+// subscribe starts monitoring for the chat. Subscriptions of groups and
+// channels are persistent and notified on every restock.
+func (b *bot) subscribe(chat *tgbotapi.Chat, lang i18n.Lang, products, countries []string) {
+	key := strconv.FormatInt(chat.ID, 10)
+
+	// Reject products sold nowhere, other unsupported combinations are
+	// reported and not checked.
+	if unoffered := b.mon.Unoffered(products, countries); len(unoffered) > 0 {
+		b.send(tgbotapi.NewMessage(chat.ID, i18n.T(lang, "not_offered", strings.Join(unoffered, ", "))))
+		return
+	}
+
+	if err := b.mon.Update(key, func(req *monitor.Request) {
+		req.Products = products
+		req.Countries = countries
+		req.ChatID = chat.ID
+		req.ChatType = chat.Type
+		req.Persistent = !chat.IsPrivate()
+
+		// Remember the detected language for notifications.
+		if req.Language == "" {
+			req.Language = lang.String()
+		}
+	}); err != nil {
+		b.log.Error("Failed to add chat to store.", "chatID", chat.ID, "error", err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chat.ID, i18n.T(lang, "monitoring_started",
+		strings.Join(products, ", "),
+		strings.Join(countries, ", "),
+	))
+
+	if !chat.IsChannel() {
+		msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	}
+
+	b.send(msg)
+
+	if unsupported := b.mon.Unsupported(products, countries); len(unsupported) > 0 {
+		b.send(tgbotapi.NewMessage(chat.ID, i18n.T(lang, "not_offered_some", strings.Join(unsupported, ", "))))
+	}
+
+	b.log.Info("New monitor added", "chatID", chat.ID, "chatType", chat.Type, "products", products, "countries", countries)
+}
+
+// promptRenewal asks the chat whether it's still interested in its expiring
+// subscription. Errors are translated for the notify.Dispatcher.
+func (b *bot) promptRenewal(_ context.Context, req monitor.Request, expires time.Time) error {
+	lang := i18n.Parse(req.Language)
+
+	_, err := b.api.Send(tgbotapi.NewMessage(req.ChatID, i18n.T(lang, "subscription_expiring",
+		strings.Join(req.Products, ", "),
+		expires.Format(time.DateOnly),
+	)))
+
+	return dispatchError(err)
+}
+
+// isAdmin reports whether the sender of the message administers the chat.
+func (b *bot) isAdmin(message *tgbotapi.Message) bool {
+	if message.From == nil {
+		return false
+	}
+
+	member := chatMember{ChatID: message.Chat.ID, UserID: message.From.ID}
+
+	return b.cachedAdmin(time.Now(), member, func() (bool, error) {
+		m, err := b.api.GetChatMember(tgbotapi.ChatConfigWithUser{
+			ChatID: member.ChatID,
+			UserID: member.UserID,
+		})
+
+		return m.IsCreator() || m.IsAdministrator(), err
+	})
+}
+
+// cachedAdmin returns the cached administrator status of the member, or
+// looks it up, so busy groups don't query Telegram for every message.
+// Failed lookups aren't cached.
+func (b *bot) cachedAdmin(now time.Time, member chatMember, lookup func() (bool, error)) bool {
+	const adminTTL = 5 * time.Minute
+
+	maps.DeleteFunc(b.admins, func(_ chatMember, status adminStatus) bool {
+		return now.Sub(status.Checked) >= adminTTL
+	})
+
+	if status, ok := b.admins[member]; ok {
+		return status.Admin
+	}
+
+	admin, err := lookup()
+	if err != nil {
+		b.log.Error("Failed to get chat member.", "chatID", member.ChatID, "error", err)
+		return false
+	}
+
+	b.admins[member] = adminStatus{Admin: admin, Checked: now}
+
+	return admin
+}
+
+// readOnlyCommand reports whether the command doesn't change the subscription.
+func readOnlyCommand(cmd string) bool {
+	return slices.Contains([]string{"/start", "/history", "/forecast", "/export"}, cmd)
+}
+
+// language returns the language chosen by the user, or the language of the
+// user's Telegram client.
+func (b *bot) language(message *tgbotapi.Message) i18n.Lang {
+	if req, ok := b.mon.Subscription(strconv.FormatInt(message.Chat.ID, 10)); ok && req.Language != "" {
+		return i18n.Parse(req.Language)
+	}
+
+	if message.From != nil {
+		return i18n.Parse(message.From.LanguageCode)
+	}
+
+	return i18n.English
+}
+
+func (b *bot) send(c tgbotapi.Chattable) {
+	if _, err := b.api.Send(c); err != nil {
+		b.log.Error("Failed to send message.", "error", err)
+	}
+}
+
+// isChatGone reports whether the Telegram error means the chat can't receive
+// messages anymore.
+func isChatGone(description string) bool {
+	for _, reason := range []string{
+		"bot was blocked by the user",
+		"user is deactivated",
+		"bot was kicked",
+		"chat not found",
+		"bot is not a member",
+	} {
+		if strings.Contains(description, reason) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowEmail reports whether a verification email may be sent for the keys
+// and records the attempt, so neither a chat nor an address can be flooded.
+func (b *bot) allowEmail(now time.Time, keys ...string) bool {
+	const emailCooldown = 5 * time.Minute
+
+	maps.DeleteFunc(b.emailSent, func(_ string, sent time.Time) bool {
+		return now.Sub(sent) >= emailCooldown
+	})
+
+	for _, k := range keys {
+		if _, ok := b.emailSent[k]; ok {
+			return false
+		}
+	}
+
+	for _, k := range keys {
+		b.emailSent[k] = now
+	}
+
+	return true
+}
+
+const (
+	// emailCodeLifetime is how long a verification code is valid.
+	emailCodeLifetime = 15 * time.Minute
+	// maxEmailAttempts is the number of wrong codes before the code is
+	// invalidated and a new one must be requested.
+	maxEmailAttempts = 5
+)
+
+const (
+	codeInvalid verifyResult = iota
+	codeValid
+	codeExpired
+)
+
+// verifyResult is the outcome of verifyEmailCode.
+type verifyResult int
+
+// verifyEmailCode checks the code against the pending email of the request.
+// A valid code enables email notifications, an expired code or too many
+// wrong ones discard the pending email.
+func verifyEmailCode(req *monitor.Request, code string, now time.Time) verifyResult {
+	if req.EmailCode == "" {
+		return codeInvalid
+	}
+
+	if req.EmailCodeExpires == nil || !now.Before(*req.EmailCodeExpires) {
+		clearEmailCode(req)
+		return codeExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(code), []byte(req.EmailCode)) != 1 {
+		if req.EmailAttempts++; req.EmailAttempts >= maxEmailAttempts {
+			clearEmailCode(req)
+			return codeExpired
+		}
+
+		return codeInvalid
+	}
+
+	if req.Channels == nil {
+		req.Channels = make(map[string]string)
+	}
+
+	req.Channels[notify.ChannelEmail] = req.PendingEmail
+	clearEmailCode(req)
+
+	return codeValid
+}
+
+// clearEmailCode discards the pending email and its verification code.
+func clearEmailCode(req *monitor.Request) {
+	req.PendingEmail, req.EmailCode = "", ""
+	req.EmailCodeExpires, req.EmailAttempts = nil, 0
+}
+
+// verificationCode returns a random 6 digit code.
+func verificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%06d", n), nil
+}
+
+// cutCommand splits the text into the command and its argument. The bot
+// username of commands in groups, e.g. "/monitor@RTXSniperBot", is removed.
+func cutCommand(text string) (string, string) {
+	cmd, arg, _ := strings.Cut(text, " ")
+
+	if strings.HasPrefix(cmd, "/") {
+		cmd, _, _ = strings.Cut(cmd, "@")
+	}
+
+	return cmd, strings.TrimSpace(arg)
+}
+
+func selection(opts []string, selected []string, confirmText string) tgbotapi.ReplyKeyboardMarkup {
+	var (
+		rows         [][]tgbotapi.KeyboardButton
+		filteredOpts []string
+	)
+
+	for _, opt := range opts {
+		if !slices.Contains(selected, opt) {
+			filteredOpts = append(filteredOpts, opt)
+		}
+	}
+
+	for _, option := range filteredOpts {
+		rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(option)))
+	}
+
+	if len(selected) > 0 {
+		rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(confirmText)))
+	}
+
+	return tgbotapi.NewReplyKeyboard(rows...)
+}
+
+// findProduct matches the query against the product names ignoring case,
+// so both "RTX 5090 FE" and "5090" resolve to the same product.
+func findProduct(products []string, query string) (string, bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return "", false
+	}
+
+	for _, p := range products {
+		if strings.Contains(strings.ToLower(p), query) {
+			return p, true
+		}
+	}
+
+	return "", false
+}
+
+// parseMaxPrice parses the arguments of /maxprice, a product followed by an
+// optional price and currency, e.g. "5090 24990 SEK". Unparsable prices fail
+// instead of removing the limit.
+func parseMaxPrice(products []string, arg string) (string, nvidia.Price, string, bool) {
+	var (
+		fields   = strings.Fields(arg)
+		currency string
+	)
+
+	if n := len(fields); n > 1 && isCurrency(fields[n-1]) {
+		currency = strings.ToUpper(fields[n-1])
+		fields = fields[:n-1]
+	}
+
+	// Product names contain digits too, e.g. "/maxprice rtx 5090".
+	if prod, ok := findProduct(products, strings.Join(fields, " ")); ok {
+		return prod, 0, currency, true
+	}
+
+	n := len(fields)
+	if n < 2 || strings.Trim(fields[n-1], "0123456789.,") != "" {
+		return "", 0, "", false
+	}
+
+	price, err := nvidia.ParsePrice(fields[n-1])
+	if err != nil {
+		return "", 0, "", false
+	}
+
+	prod, ok := findProduct(products, strings.Join(fields[:n-1], " "))
+
+	return prod, price, currency, ok
+}
+
+// isCurrency reports whether s is the currency code of an NVIDIA store.
+func isCurrency(s string) bool {
+	return slices.ContainsFunc(nvidia.Locales(), func(l nvidia.Locale) bool {
+		return strings.EqualFold(l.Currency, s)
+	})
+}
+
+// findAll matches every comma separated query against the options, it fails
+// if any of the queries doesn't match.
+func findAll(opts []string, queries string) ([]string, bool) {
+	var found []string
+
+	for _, q := range splitList(queries) {
+		opt, ok := findProduct(opts, q)
+		if !ok {
+			return nil, false
+		}
+
+		if !slices.Contains(found, opt) {
+			found = append(found, opt)
+		}
+	}
+
+	return found, len(found) > 0
+}
+
+func formatHistory(lang i18n.Lang, product string, records []history.Record) string {
+	const maxRecords = 20
+
+	if len(records) == 0 {
+		return i18n.T(lang, "history_empty", product)
+	}
+
+	if len(records) > maxRecords {
+		records = records[len(records)-maxRecords:]
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(i18n.T(lang, "history_title", product) + "\n")
+
+	for _, r := range records {
+		l, _ := nvidia.LookupLocale(nvidia.Country(r.Country))
+		sb.WriteString(i18n.T(lang, "history_record", r.Time.In(l.Location()).Format("2006-01-02 15:04 MST"), r.Country, r.Retailer, r.Stock) + "\n")
+	}
+
+	return sb.String()
+}
+
+func formatForecast(lang i18n.Lang, hist *history.Store, prod nvidia.Product, countries []string) string {
+	const numWindows = 3
+
+	var (
+		sb  strings.Builder
+		now = time.Now()
+	)
+
+	sb.WriteString(i18n.T(lang, "forecast_title", prod) + "\n")
+
+	for _, c := range countries {
+		skuCode := monitor.SKUCode(prod, nvidia.Country(c))
+
+		l, _ := nvidia.LookupLocale(nvidia.Country(c))
+		loc := l.Location()
+
+		stats := hist.Stats(skuCode, loc)
+		if stats.Drops == 0 {
+			sb.WriteString("\n" + i18n.T(lang, "forecast_no_drops", c) + "\n")
+			continue
+		}
+
+		sb.WriteString("\n" + i18n.T(lang, "forecast_stats", c, stats.DropsPerWeek, i18n.Weekday(lang, stats.TypicalDay),
+			stats.TypicalHour, now.In(loc).Format("MST"), stats.AvgInStock.Round(time.Minute)) + "\n")
+
+		for _, w := range hist.Forecast(skuCode, now, numWindows) {
+			fmt.Fprintf(&sb, "  %s - %s (%.0f%%)\n", w.Start.In(loc).Format("2006-01-02 15:04"), w.End.In(loc).Format("15:04 MST"), w.Likelihood*100)
+		}
+	}
+
+	return sb.String()
+}
+
+func formatRetailers(lang i18n.Lang, req monitor.Request) string {
+	allowed, excluded := i18n.T(lang, "retailers_all"), i18n.T(lang, "retailers_none")
+
+	if len(req.Retailers) > 0 {
+		allowed = strings.Join(req.Retailers, ", ")
+	}
+
+	if len(req.ExcludedRetailers) > 0 {
+		excluded = strings.Join(req.ExcludedRetailers, ", ")
+	}
+
+	return i18n.T(lang, "retailers", allowed, excluded)
+}
+
+func formatMaxPrices(lang i18n.Lang, req monitor.Request) string {
+	if len(req.MaxPrices) == 0 {
+		return i18n.T(lang, "maxprice_none")
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(i18n.T(lang, "maxprice_title") + "\n")
+
+	for _, prod := range slices.Sorted(maps.Keys(req.MaxPrices)) {
+		for _, currency := range slices.Sorted(maps.Keys(req.MaxPrices[prod])) {
+			fmt.Fprintf(&sb, "%s: %s %s\n", prod, nvidia.Price(req.MaxPrices[prod][currency]), currency)
+		}
+	}
+
+	return sb.String()
+}
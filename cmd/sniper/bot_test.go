@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/notify"
+)
+
+func TestVerifyEmailCode(t *testing.T) {
+	now := time.Date(2025, 2, 3, 9, 0, 0, 0, time.UTC)
+	expires := now.Add(emailCodeLifetime)
+
+	pending := func() *monitor.Request {
+		return &monitor.Request{PendingEmail: "user@example.com", EmailCode: "123456", EmailCodeExpires: &expires}
+	}
+
+	for _, tt := range []struct {
+		name    string
+		req     *monitor.Request
+		code    string
+		now     time.Time
+		want    verifyResult
+		enabled bool
+		pending bool
+	}{
+		{name: "valid", req: pending(), code: "123456", now: now, want: codeValid, enabled: true},
+		{name: "wrong code", req: pending(), code: "654321", now: now, want: codeInvalid, pending: true},
+		{name: "no pending email", req: &monitor.Request{}, code: "123456", now: now, want: codeInvalid},
+		{name: "expired", req: pending(), code: "123456", now: expires, want: codeExpired},
+		{name: "without expiry", req: &monitor.Request{PendingEmail: "user@example.com", EmailCode: "123456"}, code: "123456", now: now, want: codeExpired},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyEmailCode(tt.req, tt.code, tt.now); got != tt.want {
+				t.Errorf("verifyEmailCode() = %d, want %d", got, tt.want)
+			}
+
+			if _, ok := tt.req.Channels[notify.ChannelEmail]; ok != tt.enabled {
+				t.Errorf("email enabled = %t, want %t", ok, tt.enabled)
+			}
+
+			if ok := tt.req.EmailCode != ""; ok != tt.pending {
+				t.Errorf("code pending = %t, want %t", ok, tt.pending)
+			}
+		})
+	}
+
+	t.Run("too many attempts", func(t *testing.T) {
+		req := pending()
+
+		for i := 1; i < maxEmailAttempts; i++ {
+			if got := verifyEmailCode(req, "000000", now); got != codeInvalid {
+				t.Fatalf("attempt %d = %d, want %d", i, got, codeInvalid)
+			}
+		}
+
+		if got := verifyEmailCode(req, "000000", now); got != codeExpired {
+			t.Fatalf("last attempt = %d, want %d", got, codeExpired)
+		}
+
+		// The right code no longer works once invalidated.
+		if got := verifyEmailCode(req, "123456", now); got != codeInvalid || req.PendingEmail != "" {
+			t.Errorf("verifyEmailCode() = %d with pending %q, want %d without", got, req.PendingEmail, codeInvalid)
+		}
+	})
+}
+
+func TestCachedAdmin(t *testing.T) {
+	b := &bot{admins: make(map[chatMember]adminStatus), log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	var (
+		now     = time.Date(2025, 2, 3, 9, 0, 0, 0, time.UTC)
+		member  = chatMember{ChatID: -100, UserID: 7}
+		lookups int
+		admin   = true
+		err     error
+	)
+
+	lookup := func() (bool, error) {
+		lookups++
+		return admin, err
+	}
+
+	for _, tt := range []struct {
+		name    string
+		now     time.Time
+		setup   func()
+		want    bool
+		lookups int
+	}{
+		{name: "first message", now: now, want: true, lookups: 1},
+		{name: "cached", now: now.Add(time.Minute), setup: func() { admin = false }, want: true, lookups: 1},
+		{name: "expired", now: now.Add(5 * time.Minute), want: false, lookups: 2},
+		{name: "lookup failed", now: now.Add(10 * time.Minute), setup: func() { admin, err = true, errors.New("timeout") }, want: false, lookups: 3},
+		{name: "failure not cached", now: now.Add(10 * time.Minute), setup: func() { err = nil }, want: true, lookups: 4},
+	} {
+		if tt.setup != nil {
+			tt.setup()
+		}
+
+		if got := b.cachedAdmin(tt.now, member, lookup); got != tt.want || lookups != tt.lookups {
+			t.Errorf("%s: cachedAdmin() = %t after %d lookups, want %t after %d", tt.name, got, lookups, tt.want, tt.lookups)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+)
+
+// Exit codes of the check command.
+const (
+	checkInStock    = 0
+	checkOutOfStock = 1
+	checkError      = 2
+)
+
+type (
+	// checkSource is the stock source of the check command, see
+	// scraper.Sources.
+	checkSource interface {
+		Offers(product, country string) bool
+		Check(ctx context.Context, product, country string) ([]monitor.Offer, error)
+	}
+
+	// checkOffer is an offer in the JSON output of the check command.
+	checkOffer struct {
+		Title     string       `json:"title,omitempty"`
+		Retailer  string       `json:"retailer"`
+		PartnerID string       `json:"partnerId,omitempty"`
+		StoreID   string       `json:"storeId,omitempty"`
+		Stock     int          `json:"stock"`
+		Price     nvidia.Price `json:"price,omitempty"`
+		Currency  string       `json:"currency,omitempty"`
+		// Link is sent in notifications, PageLink is the retailer page.
+		Link     string `json:"link,omitempty"`
+		PageLink string `json:"pageLink,omitempty"`
+		// Filtered offers are excluded by the retailer filter.
+		Filtered bool `json:"filtered"`
+	}
+)
+
+// runCheck fetches the offers of a product once and prints them. The exit
+// code tells whether an offer passing the retailer filter is in stock.
+func runCheck(log *slog.Logger, cfg *config, args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	product := fs.String("product", "", `product name, e.g. "RTX 5090 FE"`)
+	country := fs.String("country", "", `country name, e.g. "Sweden"`)
+	sku := fs.String("sku", "", "raw SKU code, instead of product and country")
+	locale := fs.String("locale", "", `buy-now API locale of the raw SKU code, e.g. "se"`)
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of the request")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sniper check (-product name -country name | -sku code -locale code) [-json]")
+		fmt.Fprintln(fs.Output(), "Exits with 0 if in stock, 1 if out of stock and 2 on errors.")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return checkError
+	}
+
+	if *sku == "" && (*product == "" || *country == "") || *sku != "" && *locale == "" {
+		fs.Usage()
+		return checkError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var stocks []monitor.Offer
+
+	if *sku != "" {
+		api, err := newAPIClient(cfg)
+		if err != nil {
+			log.Error("Failed to initialize NVIDIA client.", "error", err)
+			return checkError
+		}
+
+		resp, err := api.BuyNowSKU(ctx, *sku, *locale)
+		if err != nil {
+			log.Error("Failed to get buy now links.", "sku", *sku, "locale", *locale, "error", err)
+			return checkError
+		}
+
+		stocks = monitor.NVIDIAOffers(resp)
+	} else {
+		source, err := newStockSource(log, cfg)
+		if err != nil {
+			log.Error("Failed to initialize stock sources.", "error", err)
+			return checkError
+		}
+
+		if stocks, err = checkProduct(ctx, source, *product, *country); err != nil {
+			log.Error("Failed to check stock.", "product", *product, "country", *country, "error", err)
+			return checkError
+		}
+	}
+
+	code, err := writeCheck(os.Stdout, stocks, cfg.RetailerFilter, *asJSON)
+	if err != nil {
+		log.Error("Failed to write stock data.", "error", err)
+		return checkError
+	}
+
+	return code
+}
+
+// checkProduct returns the offers of the product in the country.
+func checkProduct(ctx context.Context, source checkSource, product, country string) ([]monitor.Offer, error) {
+	if !source.Offers(product, country) {
+		return nil, fmt.Errorf("unknown product %q in %q", product, country)
+	}
+
+	return source.Check(ctx, product, country)
+}
+
+// writeCheck prints the offers as a table or JSON and returns the exit code of
+// the check command.
+func writeCheck(w io.Writer, stocks []monitor.Offer, filter monitor.RetailerFilter, asJSON bool) (int, error) {
+	code := checkOutOfStock
+	offers := make([]checkOffer, 0, len(stocks))
+
+	for _, o := range stocks {
+		if filter.Match(o) {
+			code = checkInStock
+		}
+
+		offers = append(offers, checkOffer{
+			Title:     o.Title,
+			Retailer:  o.Retailer,
+			PartnerID: o.PartnerID,
+			StoreID:   o.StoreID,
+			Stock:     o.Stock,
+			Price:     o.Price,
+			Currency:  o.Currency,
+			Link:      o.Link,
+			PageLink:  o.PageLink,
+			Filtered:  !filter.Match(o),
+		})
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return code, enc.Encode(offers)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "RETAILER\tSTOCK\tPRICE\tCURRENCY\tPARTNER\tSTORE\tFILTERED\tLINK")
+
+	for _, o := range offers {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%t\t%s\n",
+			o.Retailer, o.Stock, o.Price, o.Currency, o.PartnerID, o.StoreID, o.Filtered, o.Link)
+	}
+
+	return code, tw.Flush()
+}
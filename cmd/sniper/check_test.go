@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor/monitortest"
+)
+
+func TestCheck(t *testing.T) {
+	var (
+		nvidiaOffer = monitor.Offer{Retailer: "NVIDIA", PartnerID: "111", StoreID: "9595", Stock: 1, Price: 24990, Currency: "SEK"}
+		offer       = monitor.Offer{
+			Title:    "NVIDIA GeForce RTX 5090",
+			Retailer: "Inet",
+			Stock:    2,
+			Price:    24990,
+			Currency: "SEK",
+			Link:     "https://www.inet.se/cart/add/5090",
+			PageLink: "https://www.inet.se/produkt/5090",
+		}
+	)
+
+	tests := []struct {
+		name   string
+		offers []monitor.Offer
+		want   []checkOffer
+		code   int
+	}{
+		{
+			name: "sold out",
+			want: []checkOffer{},
+			code: checkOutOfStock,
+		},
+		{
+			name:   "filtered only",
+			offers: []monitor.Offer{nvidiaOffer},
+			want:   []checkOffer{{Retailer: "NVIDIA", PartnerID: "111", StoreID: "9595", Stock: 1, Price: 24990, Currency: "SEK", Filtered: true}},
+			code:   checkOutOfStock,
+		},
+		{
+			name:   "in stock",
+			offers: []monitor.Offer{nvidiaOffer, offer},
+			want: []checkOffer{
+				{Retailer: "NVIDIA", PartnerID: "111", StoreID: "9595", Stock: 1, Price: 24990, Currency: "SEK", Filtered: true},
+				{
+					Title:    "NVIDIA GeForce RTX 5090",
+					Retailer: "Inet",
+					Stock:    2,
+					Price:    24990,
+					Currency: "SEK",
+					Link:     "https://www.inet.se/cart/add/5090",
+					PageLink: "https://www.inet.se/produkt/5090",
+				},
+			},
+			code: checkInStock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := monitortest.NewSource()
+			source.Set("RTX 5090 FE", "Sweden", tt.offers...)
+
+			stocks, err := checkProduct(context.Background(), source, "RTX 5090 FE", "Sweden")
+			if err != nil {
+				t.Fatalf("checkProduct() error = %v", err)
+			}
+
+			var out bytes.Buffer
+
+			code, err := writeCheck(&out, stocks, monitor.DefaultRetailerFilter, true)
+			if err != nil {
+				t.Fatalf("writeCheck() error = %v", err)
+			}
+
+			if code != tt.code {
+				t.Errorf("writeCheck() = %d, want %d", code, tt.code)
+			}
+
+			var got []checkOffer
+
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("decoding %s: %v", out.String(), err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("offers = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckJSONFields(t *testing.T) {
+	offer := monitor.Offer{Retailer: "Inet", Stock: 1, Link: "https://www.inet.se/cart", PageLink: "https://www.inet.se/produkt"}
+
+	var out bytes.Buffer
+
+	if _, err := writeCheck(&out, []monitor.Offer{offer}, monitor.DefaultRetailerFilter, true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, field := range []string{`"retailer": "Inet"`, `"link": "https://www.inet.se/cart"`, `"pageLink": "https://www.inet.se/produkt"`, `"filtered": false`} {
+		if !strings.Contains(out.String(), field) {
+			t.Errorf("output %s lacks %s", out.String(), field)
+		}
+	}
+}
+
+func TestCheckUnknownProduct(t *testing.T) {
+	if _, err := checkProduct(context.Background(), monitortest.NewSource(), "RTX 5090 FE", "Sweden"); err == nil {
+		t.Error("checkProduct() succeeded for a product without offers")
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// command runs a subcommand with its arguments and returns the exit code.
+type command func(log *slog.Logger, cfg *config, args []string) int
+
+// commands are the subcommands, the bot runs without any.
+var commands = map[string]command{
+	"check":    runCheck,
+	"discover": runDiscover,
+	"export":   runExport,
+	"import":   runImport,
+	"watch":    runWatch,
+}
+
+func runCommand(log *slog.Logger, cfg *config, name string, args []string) int {
+	cmd, ok := commands[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(commands))
+		fmt.Fprintf(os.Stderr, "unknown command %q, available commands: %s\n", name, strings.Join(names, ", "))
+
+		return 2
+	}
+
+	return cmd(log, cfg, args)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/discovery"
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+)
+
+// runDiscover lists the Founders Edition cards of the NVIDIA store and
+// whether their SKU codes are in the catalog. It doesn't change the storage
+// and works next to a running bot.
+func runDiscover(log *slog.Logger, cfg *config, args []string) int {
+	fs := flag.NewFlagSet("discover", flag.ContinueOnError)
+	countries := fs.String("country", "", "comma separated country names, all if empty")
+	timeout := fs.Duration("timeout", time.Minute, "timeout of the search")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sniper discover [-country names]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	search := nvidia.Countries()
+
+	if list := splitList(*countries); len(list) > 0 {
+		search = nil
+
+		for _, c := range list {
+			if nvidia.Country(c).Locale() == "" {
+				fmt.Fprintf(fs.Output(), "unknown country %q\n", c)
+				return 2
+			}
+
+			search = append(search, nvidia.Country(c))
+		}
+	}
+
+	// Compare with the codes discovered by the bot. The storage is locked
+	// while the bot runs, so fall back to the built-in codes.
+	if st, err := openStores(cfg); err != nil {
+		log.Warn("Comparing with the built-in SKU codes only, the storage is unavailable.", "error", err)
+	} else {
+		discovery.Load(st.skus, nvidia.Catalog{})
+		st.Close()
+	}
+
+	api, err := newAPIClient(cfg)
+	if err != nil {
+		log.Error("Failed to initialize NVIDIA client.", "error", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "PRODUCT\tCOUNTRY\tSKU\tCATALOG\tSTATUS\tTITLE")
+
+	for _, c := range search {
+		products, err := api.SearchProducts(ctx, c.Locale())
+		if err != nil {
+			log.Error("Failed to search products.", "country", c, "error", err)
+			return 1
+		}
+
+		for _, p := range products {
+			if !p.IsFounderEdition || p.GPU == "" {
+				continue
+			}
+
+			prod := nvidia.FounderEdition(p.GPU)
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", prod, c, p.ProductSKU, prod.SKU(c), p.Status, p.ProductTitle)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Error("Failed to write products.", "error", err)
+		return 1
+	}
+
+	return 0
+}
+
+func discoveredProducts(skus []discovery.SKU) []nvidia.Product {
+	products := make([]nvidia.Product, 0, len(skus))
+
+	for _, s := range skus {
+		products = append(products, nvidia.Product(s.Product))
+	}
+
+	return products
+}
+
+// formatDiscoveredSKUs formats the alert of the operators about new SKUs.
+func formatDiscoveredSKUs(skus []discovery.SKU) string {
+	var sb strings.Builder
+
+	sb.WriteString("New NVIDIA SKU codes discovered:\n")
+
+	for _, s := range skus {
+		fmt.Fprintf(&sb, "\n%s in %s: %s", s.Product, s.Country, s.Code)
+
+		if s.Previous != "" {
+			fmt.Fprintf(&sb, " (was %s)", s.Previous)
+		}
+
+		if s.Title != "" {
+			fmt.Fprintf(&sb, " - %s", s.Title)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package main
+
+import "testing"
+
+func TestStoresLockJSONFiles(t *testing.T) {
+	cfg := testConfig(t)
+
+	st, err := openStores(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := openStores(cfg); err == nil {
+		t.Fatal("opened the JSON files of a running process")
+	}
+
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err = openStores(cfg)
+	if err != nil {
+		t.Fatalf("opening after close: %v", err)
+	}
+
+	st.Close()
+}
@@ -2,31 +2,65 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
-	"slices"
 	"strconv"
 	"strings"
 	"time"
+	// Time zones of the countries, also on systems without time zone data.
+	_ "time/tzdata"
 
 	"github.com/dyptan-io/rtx-sniper-bot/async"
+	"github.com/dyptan-io/rtx-sniper-bot/discovery"
+	"github.com/dyptan-io/rtx-sniper-bot/history"
 	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/notify"
 	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
 	"github.com/dyptan-io/rtx-sniper-bot/proxy"
-	"github.com/dyptan-io/rtx-sniper-bot/storage"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 )
 
+const (
+	storageDriverJSON = "json"
+	storageDriverBolt = "bolt"
+)
+
 type config struct {
 	TelegramToken  string
+	StorageDriver  string
+	DatabaseFile   string
 	StorageFile    string
+	EncryptionKeys [][]byte
+	HistoryFile    string
+	OutboxFile     string
+	SKUsFile       string
+	TemplatesDir   string
+	RetailersFile  string
 	UpdateInterval time.Duration
+	FastInterval   time.Duration
 	Workers        int
 	ProxyServers   []string
+	RetailerFilter monitor.RetailerFilter
+
+	// SubscriptionLifetime expires subscriptions without activity, zero
+	// keeps them forever.
+	SubscriptionLifetime time.Duration
+
+	// SKUDiscoveryInterval is the interval of searching new SKU codes, zero
+	// disables the search. New codes are reported to the OperatorChatIDs.
+	SKUDiscoveryInterval time.Duration
+	OperatorChatIDs      []int64
+
+	WebhookURLs           []string
+	WebhookSecret         string
+	WebhookDeadLetterFile string
+
+	SMTP notify.SMTPConfig
 }
 
 func main() {
@@ -42,249 +76,276 @@ func main() {
 		os.Exit(1)
 	}
 
-	httpClient := http.DefaultClient
-
-	if len(cfg.ProxyServers) > 0 {
-		proxyTransport, err := proxy.NewRotatingTransport(cfg.ProxyServers)
-		if err != nil {
-			log.Error("Failed to initialize proxy transport.", "error", err)
-			os.Exit(1)
-		}
-
-		httpClient = &http.Client{
-			Transport: proxyTransport,
-		}
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(log, cfg, os.Args[1], os.Args[2:]))
 	}
 
-	bot, err := tgbotapi.NewBotAPI(cfg.TelegramToken)
-	if err != nil {
-		log.Error("Failed to initialize Telegram bot.", "error", err)
+	if cfg.TelegramToken == "" {
+		log.Error("Failed to load configuration.", "error", "TELEGRAM_BOT_TOKEN is not set")
 		os.Exit(1)
 	}
 
-	file, err := os.OpenFile(cfg.StorageFile, os.O_RDWR|os.O_CREATE, 0644)
+	botAPI, err := tgbotapi.NewBotAPI(cfg.TelegramToken)
 	if err != nil {
-		log.Error("Failed to open storage file.", "error", err)
+		log.Error("Failed to initialize Telegram bot.", "error", err)
 		os.Exit(1)
 	}
 
-	defer file.Close()
-
-	store, err := storage.Load[monitor.Request](file)
+	st, err := openStores(cfg)
 	if err != nil {
 		log.Error("Failed to initialize storage.", "error", err)
 		os.Exit(1)
 	}
-	defer store.Close()
+	defer st.Close()
 
-	baseURL, err := url.Parse("https://api.nvidia.partners")
-	if err != nil {
-		log.Error("Failed to parse base URL.", "error", err)
-		os.Exit(1)
-	}
+	hist := history.New(st.history)
+
+	templates := notify.NewTemplates()
 
-	notificationCh := make(chan monitor.Notification)
-	defer close(notificationCh)
+	if cfg.TemplatesDir != "" {
+		templates, err = notify.LoadTemplates(cfg.TemplatesDir)
+		if err != nil {
+			log.Error("Failed to load notification templates.", "error", err)
+			os.Exit(1)
+		}
+	}
 
-	apiClient := nvidia.NewClient(baseURL, nvidia.WithHTTPClient(httpClient))
-	mon := monitor.New(log, store, async.NewScheduler(log), async.NewPool(), apiClient, notificationCh)
+	outbox := notify.NewOutbox(log, st.outbox)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	mon.Start(ctx, cfg.UpdateInterval, cfg.Workers)
-	log.Info("Monitoring service started", "interval", cfg.UpdateInterval, "workers", cfg.Workers)
+	monOpts := []monitor.Option{
+		monitor.WithHistory(hist),
+		monitor.WithFastPoll(cfg.FastInterval),
+		monitor.WithRetailerFilter(cfg.RetailerFilter),
+	}
 
-	updatesCh, err := bot.GetUpdatesChan(tgbotapi.NewUpdate(0))
+	if len(cfg.WebhookURLs) > 0 {
+		deadLetterFile, err := os.OpenFile(cfg.WebhookDeadLetterFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			log.Error("Failed to open webhook dead-letter file.", "error", err)
+			os.Exit(1)
+		}
+
+		defer deadLetterFile.Close()
+
+		webhook := notify.NewWebhook(cfg.WebhookURLs, cfg.WebhookSecret, notify.WithDeadLetter(deadLetterFile))
+
+		monOpts = append(monOpts, monitor.WithRestockHandler(func(n monitor.Notification) {
+			go func() {
+				if err := webhook.Notify(ctx, n); err != nil {
+					log.Error("Failed to deliver webhook.", "sku", n.SKU, "error", err)
+				}
+			}()
+		}))
+	}
+
+	source, err := newStockSource(log, cfg)
 	if err != nil {
-		log.Error("Failed to get updates.", "error", err)
+		log.Error("Failed to initialize stock sources.", "error", err)
 		os.Exit(1)
 	}
 
+	// The bot asks for renewals through the Telegram dispatcher, but both are
+	// created after the monitor.
 	var (
-		availableProducts = []string{
-			string(nvidia.ProductRTX5080),
-			string(nvidia.ProductRTX5090),
-		}
-		availableCountries = []string{
-			string(nvidia.CountrySweden),
-			string(nvidia.CountryDenmark),
-			string(nvidia.CountryFinland),
-			string(nvidia.CountryGermany),
-			string(nvidia.CountryNetherlands),
-		}
-		userSelections = make(map[int64]struct {
-			Products  []string
-			Countries []string
-		})
+		b        *bot
+		telegram *notify.Dispatcher
 	)
 
-	go func() {
-		for notif := range notificationCh {
-			buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(notif.URLs))
+	if cfg.SubscriptionLifetime > 0 {
+		monOpts = append(monOpts, monitor.WithLifetime(cfg.SubscriptionLifetime, func(_ string, req monitor.Request, expires time.Time) {
+			telegram.Send(req.ChatID, func(ctx context.Context) error {
+				return b.promptRenewal(ctx, req, expires)
+			})
+		}))
+	}
 
-			for name, u := range notif.URLs {
-				buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonURL(name, u))
-			}
+	mon := monitor.New(log, st.requests, async.NewScheduler(log), async.NewPool(), source, outbox, monOpts...)
 
-			msg := tgbotapi.NewMessage(notif.UserID, notif.Message)
-			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
-				tgbotapi.NewInlineKeyboardRow(buttons...),
-			)
+	updatesCh, err := botAPI.GetUpdatesChan(tgbotapi.NewUpdate(0))
+	if err != nil {
+		log.Error("Failed to get updates.", "error", err)
+		os.Exit(1)
+	}
 
-			if _, err := bot.Send(msg); err != nil {
-				log.Error("Failed to send message.", "error", err)
-			}
-		}
-	}()
+	notifiers := map[string]notify.Notifier{
+		notify.ChannelDiscord: notify.NewDiscord(templates, nil),
+		notify.ChannelSlack:   notify.NewSlack(templates, nil),
+	}
 
-	for update := range updatesCh {
-		if update.Message == nil {
-			continue
-		}
+	var email *notify.Email
 
-		userID := update.Message.Chat.ID
-		text := update.Message.Text
+	if cfg.SMTP.Addr != "" {
+		email = notify.NewEmail(cfg.SMTP, templates)
+		notifiers[notify.ChannelEmail] = email
+	}
 
-		if text == "/start" {
-			if _, err := bot.Send(tgbotapi.NewMessage(userID, "Welcome! Use /monitor to track product availability.")); err != nil {
-				log.Error("Failed to send message.", "error", err)
-			}
+	b = newBot(log, botAPI, mon, hist, templates, notifiers, email)
+	b.addProducts(source.Products())
 
-			continue
+	outboxDone := notify.WithDone(func(n monitor.Notification) {
+		if err := outbox.Done(n); err != nil {
+			log.Error("Failed to remove notification from outbox.", "id", n.ID, "error", err)
 		}
+	})
 
-		if text == "/monitor" {
-			msg := tgbotapi.NewMessage(userID, "Select products:")
-			msg.ReplyMarkup = selection(availableProducts, userSelections[userID].Products, "Confirm Products")
+	telegram = notify.NewDispatcher(log, b.notify, outboxDone,
+		notify.WithChatGone(func(chatID int64) {
+			mon.Remove(strconv.FormatInt(chatID, 10))
+		}),
+	)
 
-			if _, err := bot.Send(msg); err != nil {
-				log.Error("Failed to send message.", "error", err)
-			}
+	dispatchers := map[string]*notify.Dispatcher{
+		notify.ChannelTelegram: telegram,
+	}
 
-			userSelections[userID] = struct {
-				Products  []string
-				Countries []string
-			}{}
+	for channel, notifier := range notifiers {
+		dispatchers[channel] = notify.NewDispatcher(log, func(ctx context.Context, n monitor.Notification) error {
+			return notifier.Notify(ctx, n.Channels[channel], n)
+		}, outboxDone)
+	}
 
-			continue
-		}
+	for _, d := range dispatchers {
+		go d.Run(ctx, cfg.Workers)
+	}
 
-		if text == "Confirm Products" && len(userSelections[userID].Products) > 0 {
-			msg := tgbotapi.NewMessage(userID, "Select countries:")
-			msg.ReplyMarkup = selection(availableCountries, nil, "Confirm Countries")
+	if cfg.SKUDiscoveryInterval > 0 {
+		api, err := newAPIClient(cfg)
+		if err != nil {
+			log.Error("Failed to initialize NVIDIA client.", "error", err)
+			os.Exit(1)
+		}
 
-			if _, err := bot.Send(msg); err != nil {
-				log.Error("Failed to send message.", "error", err)
+		disc := discovery.New(log, api, st.skus, nvidia.Catalog{}, nvidia.Countries(), discovery.WithNewSKUs(func(skus []discovery.SKU, baseline bool) {
+			if err := mon.Reindex(); err != nil {
+				log.Error("Failed to reindex requests.", "error", err)
 			}
 
-			continue
-		}
+			b.addProducts(discoveredProducts(skus), nil)
 
-		if text == "Confirm Countries" && len(userSelections[userID].Countries) > 0 {
-			sel := userSelections[userID]
-			mon.Monitor(fmt.Sprintf("%d", userID), sel.Products, sel.Countries)
+			if !baseline {
+				b.alertOperators(cfg.OperatorChatIDs, formatDiscoveredSKUs(skus))
+			}
+		}))
 
-			msg := tgbotapi.NewMessage(userID, fmt.Sprintf("Monitoring started for %s in %s. /unmonitor to stop",
-				strings.Join(sel.Products, ", "),
-				strings.Join(sel.Countries, ", "),
-			))
-			msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+		// Load before starting the monitor, so it checks the stored codes.
+		b.addProducts(discoveredProducts(discovery.Load(st.skus, nvidia.Catalog{})), nil)
+		disc.Start(ctx, async.NewScheduler(log), cfg.SKUDiscoveryInterval)
+	}
 
-			if _, err := bot.Send(msg); err != nil {
-				log.Error("Failed to send message.", "error", err)
-			}
+	mon.Start(ctx, cfg.UpdateInterval, cfg.Workers)
+	log.Info("Monitoring service started", "interval", cfg.UpdateInterval, "workers", cfg.Workers)
 
-			log.Info("New monitor added", "userID", userID, "products", sel.Products, "countries", sel.Countries)
-			delete(userSelections, userID)
+	go outbox.Run(ctx, func(n monitor.Notification) {
+		d, ok := dispatchers[n.Channel]
+		if !ok {
+			// The channel was configured before the restart.
+			log.Warn("Dropping notification of unavailable channel.", "channel", n.Channel, "chatID", n.ChatID)
+			_ = outbox.Done(n)
 
-			continue
+			return
 		}
 
-		if slices.Contains(availableProducts, text) {
-			sel := userSelections[userID]
+		d.Enqueue(n)
+	})
 
-			// Avoid duplicate selection
-			if !slices.Contains(sel.Products, text) {
-				sel.Products = append(sel.Products, text)
-				userSelections[userID] = sel
-			}
+	for update := range updatesCh {
+		switch {
+		case update.Message != nil:
+			b.handle(update.Message)
+		case update.ChannelPost != nil:
+			b.handle(update.ChannelPost)
+		}
+	}
+}
 
-			// Send updated menu with remaining options
-			msg := tgbotapi.NewMessage(userID, fmt.Sprintf("Selected product: %s", text))
-			msg.ReplyMarkup = selection(availableProducts, sel.Products, "Confirm Products")
+// newHTTPClient returns an HTTP client using the configured proxies.
+func newHTTPClient(cfg *config) (*http.Client, error) {
+	if len(cfg.ProxyServers) == 0 {
+		return http.DefaultClient, nil
+	}
 
-			if _, err := bot.Send(msg); err != nil {
-				log.Error("Failed to send message.", "error", err)
-			}
+	proxyTransport, err := proxy.NewRotatingTransport(cfg.ProxyServers)
+	if err != nil {
+		return nil, fmt.Errorf("initializing proxy transport: %w", err)
+	}
 
-			continue
-		}
+	return &http.Client{
+		Transport: proxyTransport,
+	}, nil
+}
 
-		if slices.Contains(availableCountries, text) {
-			sel := userSelections[userID]
+// newAPIClient returns the NVIDIA API client using the configured proxies.
+func newAPIClient(cfg *config) (*nvidia.Client, error) {
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-			// Avoid duplicate selection
-			if !slices.Contains(sel.Countries, text) {
-				sel.Countries = append(sel.Countries, text)
-				userSelections[userID] = sel
-			}
+	baseURL, err := url.Parse("https://api.nvidia.partners")
+	if err != nil {
+		return nil, err
+	}
 
-			// Send updated menu with remaining options
-			msg := tgbotapi.NewMessage(userID, fmt.Sprintf("Selected country: %s", text))
-			msg.ReplyMarkup = selection(availableCountries, sel.Countries, "Confirm Countries")
+	return nvidia.NewClient(baseURL, nvidia.WithHTTPClient(httpClient)), nil
+}
 
-			if _, err := bot.Send(msg); err != nil {
-				log.Error("Failed to send message.", "error", err)
-			}
+// loadEncryptionKeys reads the base64 encoded storage encryption keys from the
+// environment or the key file, the current key first.
+func loadEncryptionKeys() ([][]byte, error) {
+	encoded := splitList(os.Getenv("STORAGE_ENCRYPTION_KEYS"))
 
-			continue
+	if keyFile := os.Getenv("STORAGE_ENCRYPTION_KEY_FILE"); keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
 		}
 
-		if text == "/unmonitor" {
-			mon.Unmonitor(fmt.Sprintf("%d", userID))
+		encoded = append(encoded, strings.Fields(string(data))...)
+	}
 
-			if _, err := bot.Send(tgbotapi.NewMessage(userID, "Monitoring stopped. Use /monitor to start again.")); err != nil {
-				log.Error("Failed to send message.", "error", err)
-			}
+	keys := make([][]byte, 0, len(encoded))
 
-			log.Info("Monitor removed", "userID", userID)
-			continue
+	for _, e := range encoded {
+		key, err := base64.StdEncoding.DecodeString(e)
+		if err != nil {
+			return nil, fmt.Errorf("decoding encryption key: %w", err)
 		}
 
-		if _, err := bot.Send(tgbotapi.NewMessage(userID, "Unknown command. Use /monitor or /unmonitor.")); err != nil {
-			log.Error("Failed to send message.", "error", err)
-		}
+		keys = append(keys, key)
 	}
+
+	return keys, nil
 }
 
-func selection(opts []string, selected []string, confirmText string) tgbotapi.ReplyKeyboardMarkup {
-	var (
-		rows         [][]tgbotapi.KeyboardButton
-		filteredOpts []string
-	)
+// splitList splits a comma separated list dropping empty elements.
+func splitList(s string) []string {
+	var list []string
 
-	for _, opt := range opts {
-		if !slices.Contains(selected, opt) {
-			filteredOpts = append(filteredOpts, opt)
+	for _, elem := range strings.Split(s, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			list = append(list, elem)
 		}
 	}
 
-	for _, option := range filteredOpts {
-		rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(option)))
-	}
+	return list
+}
 
-	if len(selected) > 0 {
-		rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(confirmText)))
+func loadConfig() (*config, error) {
+	storageDriver := os.Getenv("STORAGE_DRIVER")
+	if storageDriver == "" {
+		storageDriver = storageDriverJSON
 	}
 
-	return tgbotapi.NewReplyKeyboard(rows...)
-}
+	if storageDriver != storageDriverJSON && storageDriver != storageDriverBolt {
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", storageDriver)
+	}
 
-func loadConfig() (*config, error) {
-	telegramToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	if telegramToken == "" {
-		return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN is not set")
+	databaseFile := os.Getenv("DATABASE_FILE")
+	if databaseFile == "" {
+		databaseFile = "sniper.db"
 	}
 
 	storageFile := os.Getenv("STORAGE_FILE")
@@ -292,6 +353,26 @@ func loadConfig() (*config, error) {
 		storageFile = "db.json"
 	}
 
+	encryptionKeys, err := loadEncryptionKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load storage encryption keys: %w", err)
+	}
+
+	historyFile := os.Getenv("HISTORY_FILE")
+	if historyFile == "" {
+		historyFile = "history.json"
+	}
+
+	outboxFile := os.Getenv("OUTBOX_FILE")
+	if outboxFile == "" {
+		outboxFile = "outbox.json"
+	}
+
+	skusFile := os.Getenv("SKUS_FILE")
+	if skusFile == "" {
+		skusFile = "skus.json"
+	}
+
 	intervalStr := os.Getenv("UPDATE_INTERVAL")
 	if intervalStr == "" {
 		intervalStr = "60s"
@@ -302,6 +383,44 @@ func loadConfig() (*config, error) {
 		return nil, fmt.Errorf("failed to parse UPDATE_INTERVAL: %w", err)
 	}
 
+	var fastInterval time.Duration
+
+	if fastStr := os.Getenv("FAST_POLL_INTERVAL"); fastStr != "" {
+		fastInterval, err = time.ParseDuration(fastStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse FAST_POLL_INTERVAL: %w", err)
+		}
+	}
+
+	var lifetime time.Duration
+
+	if lifetimeStr := os.Getenv("SUBSCRIPTION_LIFETIME"); lifetimeStr != "" {
+		lifetime, err = time.ParseDuration(lifetimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SUBSCRIPTION_LIFETIME: %w", err)
+		}
+	}
+
+	var discoveryInterval time.Duration
+
+	if discoveryStr := os.Getenv("SKU_DISCOVERY_INTERVAL"); discoveryStr != "" {
+		discoveryInterval, err = time.ParseDuration(discoveryStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SKU_DISCOVERY_INTERVAL: %w", err)
+		}
+	}
+
+	var operatorChatIDs []int64
+
+	for _, id := range splitList(os.Getenv("OPERATOR_CHAT_IDS")) {
+		chatID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OPERATOR_CHAT_IDS: %w", err)
+		}
+
+		operatorChatIDs = append(operatorChatIDs, chatID)
+	}
+
 	workersStr := os.Getenv("WORKERS")
 	if workersStr == "" {
 		workersStr = "1"
@@ -314,11 +433,72 @@ func loadConfig() (*config, error) {
 
 	proxyServers := strings.Split(os.Getenv("PROXY_SERVERS"), ",")
 
+	retailerFilter := monitor.DefaultRetailerFilter
+	retailerFilter.Retailers = splitList(os.Getenv("RETAILERS"))
+	retailerFilter.ExcludedRetailers = splitList(os.Getenv("EXCLUDED_RETAILERS"))
+
+	if partners, ok := os.LookupEnv("EXCLUDED_PARTNER_IDS"); ok {
+		retailerFilter.ExcludedPartners = splitList(partners)
+	}
+
+	if stores, ok := os.LookupEnv("EXCLUDED_STORE_IDS"); ok {
+		retailerFilter.ExcludedStores = splitList(stores)
+	}
+
+	webhookURLs := splitList(os.Getenv("WEBHOOK_URLS"))
+	webhookSecret := os.Getenv("WEBHOOK_SECRET")
+
+	// Receivers couldn't verify the signature made with an empty key.
+	if len(webhookURLs) > 0 && webhookSecret == "" {
+		return nil, fmt.Errorf("WEBHOOK_SECRET is required with WEBHOOK_URLS")
+	}
+
+	deadLetterFile := os.Getenv("WEBHOOK_DEAD_LETTER_FILE")
+	if deadLetterFile == "" {
+		deadLetterFile = "webhook-dead-letter.jsonl"
+	}
+
+	smtpStartTLS := true
+
+	if startTLS := os.Getenv("SMTP_STARTTLS"); startTLS != "" {
+		smtpStartTLS, err = strconv.ParseBool(startTLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SMTP_STARTTLS: %w", err)
+		}
+	}
+
 	return &config{
-		TelegramToken:  telegramToken,
+		TelegramToken:  os.Getenv("TELEGRAM_BOT_TOKEN"),
+		StorageDriver:  storageDriver,
+		DatabaseFile:   databaseFile,
 		StorageFile:    storageFile,
+		EncryptionKeys: encryptionKeys,
+		HistoryFile:    historyFile,
+		OutboxFile:     outboxFile,
+		SKUsFile:       skusFile,
+		TemplatesDir:   os.Getenv("TEMPLATES_DIR"),
+		RetailersFile:  os.Getenv("RETAILERS_FILE"),
 		UpdateInterval: updateInterval,
+		FastInterval:   fastInterval,
 		Workers:        workers,
 		ProxyServers:   proxyServers,
+		RetailerFilter: retailerFilter,
+
+		SubscriptionLifetime: lifetime,
+
+		SKUDiscoveryInterval: discoveryInterval,
+		OperatorChatIDs:      operatorChatIDs,
+
+		WebhookURLs:           webhookURLs,
+		WebhookSecret:         webhookSecret,
+		WebhookDeadLetterFile: deadLetterFile,
+
+		SMTP: notify.SMTPConfig{
+			Addr:     os.Getenv("SMTP_ADDR"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+			StartTLS: smtpStartTLS,
+		},
 	}, nil
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/scraper"
+)
+
+// newStockSource returns the NVIDIA API extended by the scrapers of the
+// configured retailers.
+func newStockSource(log *slog.Logger, cfg *config) (*scraper.Sources, error) {
+	api, err := newAPIClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("initializing NVIDIA client: %w", err)
+	}
+
+	if cfg.RetailersFile == "" {
+		return scraper.NewSources(log, monitor.NewNVIDIASource(api)), nil
+	}
+
+	retailers, err := scraper.LoadRetailers(cfg.RetailersFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading retailers: %w", err)
+	}
+
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	scrapers := make([]*scraper.Scraper, 0, len(retailers))
+
+	for _, r := range retailers {
+		s, err := scraper.New(r, scraper.WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, err
+		}
+
+		scrapers = append(scrapers, s)
+	}
+
+	return scraper.NewSources(log, monitor.NewNVIDIASource(api), scrapers...), nil
+}
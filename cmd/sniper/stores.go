@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/discovery"
+	"github.com/dyptan-io/rtx-sniper-bot/history"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// stores holds the persistent state of the bot.
+type stores struct {
+	db *bolt.DB
+	// lock keeps other processes from opening the JSON files, bolt locks the
+	// database itself.
+	lock     *os.File
+	requests storage.Store[monitor.Request]
+	history  storage.Store[history.Record]
+	outbox   storage.Store[monitor.Notification]
+	skus     storage.Store[discovery.SKU]
+}
+
+// openStores opens the stores of the configured storage driver.
+func openStores(cfg *config) (*stores, error) {
+	var (
+		s    stores
+		opts []storage.Option
+		err  error
+	)
+
+	if cfg.StorageDriver == storageDriverBolt {
+		s.db, err = bolt.Open(cfg.DatabaseFile, 0644, &bolt.Options{Timeout: time.Second})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Another process would overwrite the changes of this one.
+		s.lock, err = lockFile(cfg.StorageFile + ".lock")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cfg.EncryptionKeys) > 0 {
+		opts = append(opts, storage.WithEncryption(cfg.EncryptionKeys...))
+	}
+
+	s.requests, err = openStore[monitor.Request](s.db, "requests", cfg.StorageFile,
+		append([]storage.Option{storage.WithMigrations(monitor.RequestMigrations...)}, opts...)...)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	s.history, err = openStore[history.Record](s.db, "history", cfg.HistoryFile,
+		append([]storage.Option{storage.WithKeyMigrations(history.Migrations...)}, opts...)...)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	s.outbox, err = openStore[monitor.Notification](s.db, "outbox", cfg.OutboxFile, opts...)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	s.skus, err = openStore[discovery.SKU](s.db, "skus", cfg.SKUsFile, opts...)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// openStore opens the named store in the database, or in its JSON file if no
+// database is used. The store is nil on errors, a typed nil pointer would
+// make it non-nil for stores.Close.
+func openStore[T any](db *bolt.DB, name, file string, opts ...storage.Option) (storage.Store[T], error) {
+	if db != nil {
+		s, err := storage.OpenBolt[T](db, name, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return s, nil
+	}
+
+	s, err := storage.Open[T](file, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", file, err)
+	}
+
+	return s, nil
+}
+
+func (s *stores) Close() error {
+	var errs []error
+
+	if s.requests != nil {
+		errs = append(errs, s.requests.Close())
+	}
+
+	if s.history != nil {
+		errs = append(errs, s.history.Close())
+	}
+
+	if s.outbox != nil {
+		errs = append(errs, s.outbox.Close())
+	}
+
+	if s.skus != nil {
+		errs = append(errs, s.skus.Close())
+	}
+
+	if s.db != nil {
+		errs = append(errs, s.db.Close())
+	}
+
+	if s.lock != nil {
+		errs = append(errs, s.lock.Close())
+	}
+
+	return errors.Join(errs...)
+}
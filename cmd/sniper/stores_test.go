@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestOpenStoresCorruptFile(t *testing.T) {
+	cfg := testConfig(t)
+
+	if err := os.WriteFile(cfg.HistoryFile, []byte(`{"version":1,"items":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The stores opened before are closed without panicking.
+	if _, err := openStores(cfg); err == nil || !strings.Contains(err.Error(), cfg.HistoryFile) {
+		t.Fatalf("openStores() error = %v, want an error naming %s", err, cfg.HistoryFile)
+	}
+
+	// The lock is released.
+	if err := os.Remove(cfg.HistoryFile); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := openStores(cfg)
+	if err != nil {
+		t.Fatalf("openStores() error = %v after removing the corrupt file", err)
+	}
+
+	st.Close()
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/dyptan-io/rtx-sniper-bot/async"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/notify"
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+// watchKey is the key of the only request of the headless monitor.
+const watchKey = "watch"
+
+// runWatch runs the monitor without Telegram and emits restocks as JSON
+// events to stdout, a command and webhooks.
+func runWatch(log *slog.Logger, cfg *config, args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	products := fs.String("product", "", `comma separated product names, e.g. "RTX 5090 FE"`)
+	countries := fs.String("country", "", `comma separated country names, e.g. "Sweden"`)
+	maxPrice := fs.Float64("max-price", 0, "ignore offers above the price, 0 for any price")
+	currency := fs.String("currency", "", "currency of the max price, the currency of the countries if empty")
+	command := fs.String("exec", "", "run the command with the event JSON on stdin, arguments are split at spaces")
+	webhooks := fs.String("webhook", strings.Join(cfg.WebhookURLs, ","), "comma separated webhook URLs, signed with WEBHOOK_SECRET")
+	interval := fs.Duration("interval", cfg.UpdateInterval, "interval between checks")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sniper watch -product names -country names [-exec command] [-webhook urls]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	req := monitor.Request{
+		Products:  splitList(*products),
+		Countries: splitList(*countries),
+		// Notify every restock instead of only the first one.
+		Persistent: true,
+	}
+
+	source, err := newStockSource(log, cfg)
+	if err != nil {
+		log.Error("Failed to initialize stock sources.", "error", err)
+		return 1
+	}
+
+	for _, p := range req.Products {
+		for _, c := range req.Countries {
+			if !source.Offers(p, c) {
+				fmt.Fprintf(fs.Output(), "unknown product %q in %q\n", p, c)
+				return 2
+			}
+		}
+	}
+
+	if len(req.Products) == 0 || len(req.Countries) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	if *maxPrice > 0 {
+		if *currency == "" {
+			if currencies := req.Currencies(); len(currencies) == 1 {
+				*currency = currencies[0]
+			}
+		}
+
+		if *currency == "" {
+			fmt.Fprintln(fs.Output(), "-max-price needs -currency for countries with different currencies")
+			return 2
+		}
+
+		req.MaxPrices = make(map[string]map[string]float64)
+
+		for _, p := range req.Products {
+			req.MaxPrices[p] = map[string]float64{strings.ToUpper(*currency): *maxPrice}
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var webhook *notify.Webhook
+
+	if urls := splitList(*webhooks); len(urls) > 0 {
+		if cfg.WebhookSecret == "" {
+			fmt.Fprintln(fs.Output(), "-webhook needs WEBHOOK_SECRET to sign the events")
+			return 2
+		}
+
+		webhook = notify.NewWebhook(urls, cfg.WebhookSecret)
+	}
+
+	// The command and the webhooks run in order in the background, so they
+	// don't hold up the stock checks.
+	deliver := func(ctx context.Context, n monitor.Notification) error {
+		if args := strings.Fields(*command); len(args) > 0 {
+			event, err := json.Marshal(notify.NewEvent(n))
+			if err != nil {
+				return err
+			}
+
+			cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+			cmd.Stdin = bytes.NewReader(event)
+			cmd.Stdout = os.Stderr
+			cmd.Stderr = os.Stderr
+
+			if err := cmd.Run(); err != nil {
+				log.Error("Failed to run command.", "command", *command, "error", err)
+			}
+		}
+
+		if webhook != nil {
+			if err := webhook.Notify(ctx, n); err != nil {
+				log.Error("Failed to deliver webhook.", "sku", n.SKU, "error", err)
+			}
+		}
+
+		return nil
+	}
+
+	dispatcher := notify.NewDispatcher(log, deliver, notify.WithRateLimits(0, 0, 0), notify.WithAttempts(1))
+
+	go dispatcher.Run(ctx, 1)
+
+	emit := func(n monitor.Notification) error {
+		event, err := json.Marshal(notify.NewEvent(n))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(event))
+
+		if *command != "" || webhook != nil {
+			dispatcher.Enqueue(n)
+		}
+
+		return nil
+	}
+
+	store := storage.New[monitor.Request]()
+
+	if err := store.Add(watchKey, req); err != nil {
+		log.Error("Failed to add request.", "error", err)
+		return 1
+	}
+
+	mon := monitor.New(log, store, async.NewScheduler(log), async.NewPool(), source, monitor.OutboxFunc(emit),
+		monitor.WithRetailerFilter(cfg.RetailerFilter))
+
+	mon.Start(ctx, *interval, cfg.Workers)
+	log.Info("Watching products.", "products", req.Products, "countries", req.Countries, "interval", *interval)
+
+	<-ctx.Done()
+
+	return 0
+}
@@ -0,0 +1,191 @@
+// Package discovery finds the SKU codes of Founders Edition cards with the
+// NVIDIA product search and adds them to the catalog.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/async"
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+type (
+	// SKU is a discovered SKU code of a product in a country.
+	SKU struct {
+		Product string `json:"product"`
+		Country string `json:"country"`
+		Code    string `json:"code"`
+		// Previous is the replaced code, empty for new products.
+		Previous string    `json:"previous,omitempty"`
+		Title    string    `json:"title,omitempty"`
+		Time     time.Time `json:"time"`
+	}
+
+	// Searcher lists the products of the NVIDIA store, e.g. the nvidia.Client.
+	Searcher interface {
+		SearchProducts(ctx context.Context, locale string) ([]nvidia.ProductDetails, error)
+	}
+
+	// Catalog holds the SKU codes of the products, e.g. nvidia.Catalog.
+	Catalog interface {
+		SKU(p nvidia.Product, c nvidia.Country) string
+		AddSKU(p nvidia.Product, c nvidia.Country, sku string)
+	}
+
+	Discoverer struct {
+		searcher  Searcher
+		store     storage.Store[SKU]
+		catalog   Catalog
+		countries []nvidia.Country
+		onNew     func(skus []SKU, baseline bool)
+		log       *slog.Logger
+	}
+
+	Option func(*Discoverer)
+)
+
+func New(log *slog.Logger, searcher Searcher, store storage.Store[SKU], catalog Catalog, countries []nvidia.Country, opts ...Option) *Discoverer {
+	d := Discoverer{
+		searcher:  searcher,
+		store:     store,
+		catalog:   catalog,
+		countries: countries,
+		log:       log,
+	}
+
+	for _, opt := range opts {
+		opt(&d)
+	}
+
+	return &d
+}
+
+// WithNewSKUs calls fn with the SKUs found by a search that weren't in the
+// catalog, e.g. to alert the operators. baseline is true for the first search
+// with an empty store, whose SKUs were there before the discovery and aren't
+// worth an alert.
+func WithNewSKUs(fn func(skus []SKU, baseline bool)) Option {
+	return func(d *Discoverer) {
+		d.onNew = fn
+	}
+}
+
+// Start searches new SKUs right away and then at the interval. The stored
+// SKUs should be loaded first, see Load.
+func (d *Discoverer) Start(ctx context.Context, sch *async.Scheduler, interval time.Duration) {
+	run := func(ctx context.Context) error {
+		_, err := d.Discover(ctx)
+		return err
+	}
+
+	go func() {
+		if err := run(ctx); err != nil {
+			d.log.Error("Failed to discover SKUs.", "error", err)
+		}
+	}()
+
+	sch.Schedule(ctx, interval, run)
+}
+
+// Load adds the stored SKUs to the catalog and returns them.
+func Load(store storage.Store[SKU], catalog Catalog) []SKU {
+	var skus []SKU
+
+	for _, sku := range store.All() {
+		catalog.AddSKU(nvidia.Product(sku.Product), nvidia.Country(sku.Country), sku.Code)
+		skus = append(skus, sku)
+	}
+
+	return skus
+}
+
+// Discover searches the Founders Edition cards of all countries and adds
+// those with unknown SKU codes to the catalog. It returns the new SKUs and
+// fails if any search fails.
+//
+// The first search with an empty store is the baseline: it stores all codes
+// found, also the ones in the catalog, so later searches tell additions apart.
+func (d *Discoverer) Discover(ctx context.Context) ([]SKU, error) {
+	var (
+		found    []SKU
+		errs     []error
+		now      = time.Now()
+		baseline = d.empty()
+	)
+
+	for _, country := range d.countries {
+		locale := country.Locale()
+		if locale == "" {
+			continue
+		}
+
+		products, err := d.searcher.SearchProducts(ctx, locale)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", country, err))
+			continue
+		}
+
+		for _, p := range products {
+			if !p.IsFounderEdition || p.ProductSKU == "" || p.GPU == "" {
+				continue
+			}
+
+			prod := nvidia.FounderEdition(p.GPU)
+
+			known := d.catalog.SKU(prod, country)
+			if known == p.ProductSKU && !baseline {
+				continue
+			}
+
+			sku := SKU{
+				Product: prod.String(),
+				Country: country.String(),
+				Code:    p.ProductSKU,
+				Title:   p.ProductTitle,
+				Time:    now,
+			}
+
+			if known != p.ProductSKU {
+				sku.Previous = known
+			}
+
+			if err := d.store.Add(key(prod, country), sku); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			if known == p.ProductSKU {
+				continue
+			}
+
+			d.catalog.AddSKU(prod, country, sku.Code)
+			d.log.Info("Discovered SKU.", "product", sku.Product, "country", sku.Country, "sku", sku.Code, "previous", sku.Previous, "baseline", baseline)
+
+			found = append(found, sku)
+		}
+	}
+
+	if len(found) > 0 && d.onNew != nil {
+		d.onNew(found, baseline)
+	}
+
+	return found, errors.Join(errs...)
+}
+
+// empty reports whether no SKUs were stored yet.
+func (d *Discoverer) empty() bool {
+	for range d.store.All() {
+		return false
+	}
+
+	return true
+}
+
+func key(prod nvidia.Product, country nvidia.Country) string {
+	return country.String() + "/" + prod.String()
+}
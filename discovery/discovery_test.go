@@ -0,0 +1,192 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+type (
+	// searcher returns the products set by locale.
+	searcher struct {
+		products map[string][]nvidia.ProductDetails
+		errs     map[string]error
+	}
+
+	// catalog is a Catalog in memory.
+	catalog map[nvidia.Country]map[nvidia.Product]string
+
+	// alert is a call of the WithNewSKUs func.
+	alert struct {
+		skus     []SKU
+		baseline bool
+	}
+)
+
+func (s *searcher) SearchProducts(_ context.Context, locale string) ([]nvidia.ProductDetails, error) {
+	return s.products[locale], s.errs[locale]
+}
+
+func (c catalog) SKU(p nvidia.Product, country nvidia.Country) string {
+	return c[country][p]
+}
+
+func (c catalog) AddSKU(p nvidia.Product, country nvidia.Country, sku string) {
+	if c[country] == nil {
+		c[country] = make(map[nvidia.Product]string)
+	}
+
+	c[country][p] = sku
+}
+
+func founderEdition(gpu, sku string) nvidia.ProductDetails {
+	return nvidia.ProductDetails{ProductTitle: "NVIDIA GeForce " + gpu, ProductSKU: sku, GPU: gpu, IsFounderEdition: true}
+}
+
+func newDiscoverer(t *testing.T, s *searcher, c catalog) (*Discoverer, storage.Store[SKU], *[]alert) {
+	t.Helper()
+
+	var (
+		store  = storage.New[SKU]()
+		alerts []alert
+		log    = slog.New(slog.NewTextHandler(io.Discard, nil))
+	)
+
+	d := New(log, s, store, c, []nvidia.Country{nvidia.CountrySweden, nvidia.CountryGermany}, WithNewSKUs(func(skus []SKU, baseline bool) {
+		alerts = append(alerts, alert{skus: skus, baseline: baseline})
+	}))
+
+	return d, store, &alerts
+}
+
+func codes(skus []SKU) []string {
+	var codes []string
+
+	for _, s := range skus {
+		codes = append(codes, s.Country+"/"+s.Product+"="+s.Code)
+	}
+
+	slices.Sort(codes)
+
+	return codes
+}
+
+func TestDiscover(t *testing.T) {
+	var (
+		sweden  = nvidia.CountrySweden.Locale()
+		germany = nvidia.CountryGermany.Locale()
+		s       = &searcher{products: map[string][]nvidia.ProductDetails{
+			sweden: {
+				founderEdition("RTX 5090", "1147625"),
+				founderEdition("RTX 5080", "1147624"),
+				// Partner cards and products without a code are skipped.
+				{ProductSKU: "900", GPU: "RTX 5090"},
+				founderEdition("RTX 5070", ""),
+			},
+			germany: {founderEdition("RTX 5090", "1145543")},
+		}}
+		c = catalog{
+			nvidia.CountrySweden:  {nvidia.ProductRTX5090: "1147625"},
+			nvidia.CountryGermany: {nvidia.ProductRTX5090: "1145543"},
+		}
+	)
+
+	d, store, alerts := newDiscoverer(t, s, c)
+
+	// The first search records the baseline without alerting.
+	found, err := d.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	if want := []string{"Sweden/RTX 5080 FE=1147624"}; !slices.Equal(codes(found), want) {
+		t.Errorf("baseline found %v, want %v", codes(found), want)
+	}
+
+	if len(*alerts) != 1 || !(*alerts)[0].baseline {
+		t.Fatalf("alerts = %+v, want one baseline", *alerts)
+	}
+
+	if got := c.SKU(nvidia.ProductRTX5080, nvidia.CountrySweden); got != "1147624" {
+		t.Errorf("catalog code = %q, want the discovered code", got)
+	}
+
+	if n := len(maps.Collect(store.All())); n != 3 {
+		t.Errorf("stored %d SKUs, want all 3 of the baseline", n)
+	}
+
+	// Unchanged codes aren't found again.
+	if found, err := d.Discover(context.Background()); err != nil || len(found) != 0 {
+		t.Fatalf("Discover() = %v, %v, want nothing new", codes(found), err)
+	}
+
+	// A later launch and a changed code are alerted.
+	s.products[sweden] = append(s.products[sweden], founderEdition("RTX 5070", "1150001"))
+	s.products[germany] = []nvidia.ProductDetails{founderEdition("RTX 5090", "1149999")}
+
+	found, err = d.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	if want := []string{"Germany/RTX 5090 FE=1149999", "Sweden/RTX 5070 FE=1150001"}; !slices.Equal(codes(found), want) {
+		t.Errorf("found %v, want %v", codes(found), want)
+	}
+
+	if len(*alerts) != 2 || (*alerts)[1].baseline {
+		t.Fatalf("alerts = %+v, want an alert after the baseline", *alerts)
+	}
+
+	if sku, _ := store.Get("Germany/RTX 5090 FE"); sku.Previous != "1145543" {
+		t.Errorf("stored %+v, want the replaced code", sku)
+	}
+}
+
+func TestDiscoverSearchFails(t *testing.T) {
+	var (
+		s = &searcher{
+			products: map[string][]nvidia.ProductDetails{
+				nvidia.CountryGermany.Locale(): {founderEdition("RTX 5080", "1145548")},
+			},
+			errs: map[string]error{nvidia.CountrySweden.Locale(): errors.New("503 Service Unavailable")},
+		}
+		c = catalog{}
+	)
+
+	d, _, _ := newDiscoverer(t, s, c)
+
+	// The other countries are searched anyway.
+	found, err := d.Discover(context.Background())
+	if err == nil {
+		t.Error("Discover() succeeded although a search failed")
+	}
+
+	if want := []string{"Germany/RTX 5080 FE=1145548"}; !slices.Equal(codes(found), want) {
+		t.Errorf("found %v, want %v", codes(found), want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	store := storage.New[SKU]()
+
+	if err := store.Add("Sweden/RTX 5070 FE", SKU{Product: "RTX 5070 FE", Country: "Sweden", Code: "1150001"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := catalog{}
+
+	if skus := Load(store, c); len(skus) != 1 {
+		t.Errorf("Load() = %+v, want the stored SKU", skus)
+	}
+
+	if got := c.SKU("RTX 5070 FE", nvidia.CountrySweden); got != "1150001" {
+		t.Errorf("catalog code = %q, want the stored code", got)
+	}
+}
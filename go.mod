@@ -4,10 +4,12 @@ go 1.23.4
 
 require (
 	github.com/go-telegram-bot-api/telegram-bot-api v4.6.4+incompatible
+	go.etcd.io/bbolt v1.3.8
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 )
 
 require (
 	github.com/technoweenie/multipartstreamer v1.0.1 // indirect
 	golang.org/x/net v0.34.0
+	golang.org/x/sys v0.29.0 // indirect
 )
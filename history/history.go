@@ -0,0 +1,147 @@
+package history
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+const (
+	// keyTimeLayout sorts the keys of a SKU by time.
+	keyTimeLayout = "20060102T150405.000000000Z"
+
+	indexSKU = "sku"
+)
+
+type (
+	// Record is a single observed stock transition of a retailer offer.
+	Record struct {
+		SKU      string    `json:"sku"`
+		Product  string    `json:"product"`
+		Country  string    `json:"country"`
+		Retailer string    `json:"retailer"`
+		Stock    int       `json:"stock"`
+		Price    string    `json:"price,omitempty"`
+		Currency string    `json:"currency,omitempty"`
+		Time     time.Time `json:"time"`
+	}
+
+	// Store keeps every stock transition under a key of its own, ordered by
+	// SKU and time, so adding a record doesn't rewrite the history of the SKU.
+	Store struct {
+		store storage.Store[Record]
+	}
+)
+
+func New(store storage.Store[Record]) *Store {
+	return &Store{
+		store: store,
+	}
+}
+
+// Add stores the record.
+func (s *Store) Add(r Record) error {
+	return s.store.Add(r.Key(), r)
+}
+
+// Key returns the key of the record, e.g.
+// "1147625/20250130T140000.000000000Z/Komplett".
+func (r Record) Key() string {
+	return r.SKU + "/" + r.Time.UTC().Format(keyTimeLayout) + "/" + r.Retailer
+}
+
+// Indexes indexes the records by SKU.
+func (r Record) Indexes() map[string][]string {
+	return map[string][]string{
+		indexSKU: {r.SKU},
+	}
+}
+
+// Product returns all records of the product ordered by time.
+func (s *Store) Product(product string) []Record {
+	return s.filter(func(r Record) bool {
+		return strings.EqualFold(r.Product, product)
+	})
+}
+
+// SKU returns all records of the SKU ordered by time.
+func (s *Store) SKU(sku string) []Record {
+	var records []Record
+
+	for _, r := range s.store.Find(indexSKU, sku) {
+		records = append(records, r)
+	}
+
+	slices.SortFunc(records, func(a, b Record) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return records
+}
+
+// Stats computes restock statistics of the SKU with hours and weekdays in the
+// location.
+func (s *Store) Stats(sku string, loc *time.Location) Stats {
+	return Compute(sku, s.SKU(sku), loc)
+}
+
+// Forecast predicts up to n windows of the next SKU drop after now.
+func (s *Store) Forecast(sku string, now time.Time, n int) []Window {
+	return Forecast(s.SKU(sku), now, n)
+}
+
+// All returns all records ordered by time.
+func (s *Store) All() []Record {
+	return s.filter(func(Record) bool {
+		return true
+	})
+}
+
+func (s *Store) filter(fn func(Record) bool) []Record {
+	var records []Record
+
+	for _, r := range s.store.All() {
+		if fn(r) {
+			records = append(records, r)
+		}
+	}
+
+	slices.SortFunc(records, func(a, b Record) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return records
+}
+
+// WriteCSV writes the records to w as CSV with a header row.
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"time", "sku", "product", "country", "retailer", "stock", "price", "currency"}); err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if err := cw.Write([]string{
+			r.Time.UTC().Format(time.RFC3339),
+			r.SKU,
+			r.Product,
+			r.Country,
+			r.Retailer,
+			strconv.Itoa(r.Stock),
+			r.Price,
+			r.Currency,
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,139 @@
+package history
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var testRecords = []Record{
+	{SKU: "1147625", Product: "RTX 5090 FE", Country: "Sweden", Retailer: "Komplett", Stock: 3, Time: time.Date(2025, 1, 30, 14, 0, 0, 0, time.UTC)},
+	{SKU: "1147625", Product: "RTX 5090 FE", Country: "Sweden", Retailer: "Inet", Stock: 1, Time: time.Date(2025, 1, 30, 14, 0, 0, 0, time.UTC)},
+	{SKU: "1147625", Product: "RTX 5090 FE", Country: "Sweden", Retailer: "Komplett", Time: time.Date(2025, 1, 30, 14, 5, 0, 0, time.UTC)},
+	{SKU: "1147626", Product: "RTX 5080 FE", Country: "Sweden", Retailer: "Komplett", Stock: 2, Time: time.Date(2025, 1, 29, 9, 0, 0, 0, time.UTC)},
+}
+
+// legacyItems returns the records grouped by SKU like they were stored before
+// keys per record.
+func legacyItems() map[string][]Record {
+	items := make(map[string][]Record)
+
+	for _, r := range testRecords {
+		items[r.SKU] = append(items[r.SKU], r)
+	}
+
+	return items
+}
+
+func TestStoreMigratesLegacyRecords(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		open func(t *testing.T) storage.Store[Record]
+	}{
+		{"json", func(t *testing.T) storage.Store[Record] {
+			name := filepath.Join(t.TempDir(), "history.json")
+
+			data, err := json.Marshal(legacyItems())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(name, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			store, err := storage.Open[Record](name, storage.WithKeyMigrations(Migrations...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return store
+		}},
+		{"bolt", func(t *testing.T) storage.Store[Record] {
+			db, err := bolt.Open(filepath.Join(t.TempDir(), "sniper.db"), 0644, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() { db.Close() })
+
+			legacy, err := storage.OpenBolt[[]Record](db, "history")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for sku, records := range legacyItems() {
+				if err := legacy.Add(sku, records); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			store, err := storage.OpenBolt[Record](db, "history", storage.WithKeyMigrations(Migrations...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return store
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.open(t)
+			defer store.Close()
+
+			want := []string{
+				"1147625/20250130T140000.000000000Z/Inet",
+				"1147625/20250130T140000.000000000Z/Komplett",
+				"1147625/20250130T140500.000000000Z/Komplett",
+				"1147626/20250129T090000.000000000Z/Komplett",
+			}
+
+			if keys := slices.Sorted(maps.Keys(maps.Collect(store.All()))); !slices.Equal(keys, want) {
+				t.Errorf("keys = %q, want %q", keys, want)
+			}
+
+			hist := New(store)
+
+			if got := hist.SKU("1147625"); len(got) != 3 || got[2] != testRecords[2] {
+				t.Errorf("SKU() = %v", got)
+			}
+
+			if got := hist.Product("rtx 5080 fe"); len(got) != 1 || got[0] != testRecords[3] {
+				t.Errorf("Product() = %v", got)
+			}
+		})
+	}
+}
+
+func TestStoreAdd(t *testing.T) {
+	store := storage.New[Record]()
+	hist := New(store)
+
+	// Added out of order.
+	for _, i := range []int{2, 0, 3, 1} {
+		if err := hist.Add(testRecords[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := len(maps.Collect(store.All())); got != len(testRecords) {
+		t.Errorf("stored %d items, want one per record", got)
+	}
+
+	all := hist.All()
+
+	if !slices.IsSortedFunc(all, func(a, b Record) int { return a.Time.Compare(b.Time) }) || len(all) != len(testRecords) {
+		t.Errorf("All() = %v, want all records ordered by time", all)
+	}
+
+	if got := hist.SKU("1147626"); len(got) != 1 || got[0] != testRecords[3] {
+		t.Errorf("SKU() = %v", got)
+	}
+}
@@ -0,0 +1,36 @@
+package history
+
+import (
+	"encoding/json"
+
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+// Migrations upgrade stored records to the current version, see
+// storage.WithKeyMigrations. Append new migrations, never change existing ones.
+var Migrations = []storage.KeyMigration{
+	migrateRecordKeys,
+}
+
+// migrateRecordKeys stores the records, which were kept in a list per SKU,
+// under keys of their own.
+func migrateRecordKeys(_ string, item json.RawMessage) (map[string]json.RawMessage, error) {
+	var records []Record
+
+	if err := json.Unmarshal(item, &records); err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]json.RawMessage, len(records))
+
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+
+		items[r.Key()] = data
+	}
+
+	return items, nil
+}
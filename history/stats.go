@@ -0,0 +1,173 @@
+package history
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+type (
+	// Drop is a period during which at least one retailer had the SKU in stock.
+	Drop struct {
+		Start time.Time
+		End   time.Time
+	}
+
+	// Stats summarizes the restock behaviour of a single SKU.
+	Stats struct {
+		SKU           string
+		Drops         int
+		DropsPerWeek  float64
+		TypicalHour   int
+		TypicalDay    time.Weekday
+		AvgInStock    time.Duration
+		LastDropStart time.Time
+	}
+
+	// Window is a predicted time range of the next drop.
+	Window struct {
+		Start      time.Time
+		End        time.Time
+		Likelihood float64
+	}
+)
+
+// Drops reconstructs the drops of the SKU from its retailer transitions.
+// A drop that is still ongoing at the last record has a zero End.
+func Drops(records []Record) []Drop {
+	var (
+		drops  []Drop
+		stocks = make(map[string]int)
+		total  int
+	)
+
+	for _, r := range records {
+		total += r.Stock - stocks[r.Retailer]
+		stocks[r.Retailer] = r.Stock
+
+		switch {
+		case total > 0 && (len(drops) == 0 || !drops[len(drops)-1].End.IsZero()):
+			drops = append(drops, Drop{Start: r.Time})
+		case total <= 0 && len(drops) > 0 && drops[len(drops)-1].End.IsZero():
+			drops[len(drops)-1].End = r.Time
+		}
+	}
+
+	return drops
+}
+
+// Compute calculates statistics of the SKU records ordered by time.
+// Hours and weekdays are reported in the location.
+func Compute(sku string, records []Record, loc *time.Location) Stats {
+	stats := Stats{SKU: sku}
+
+	drops := Drops(records)
+	if len(drops) == 0 {
+		return stats
+	}
+
+	var (
+		hours    [24]int
+		days     [7]int
+		inStock  time.Duration
+		finished int
+	)
+
+	for _, d := range drops {
+		start := d.Start.In(loc)
+		hours[start.Hour()]++
+		days[start.Weekday()]++
+
+		if !d.End.IsZero() {
+			inStock += d.End.Sub(d.Start)
+			finished++
+		}
+	}
+
+	stats.Drops = len(drops)
+	stats.TypicalHour = maxIndex(hours[:])
+	stats.TypicalDay = time.Weekday(maxIndex(days[:]))
+	stats.LastDropStart = drops[len(drops)-1].Start
+
+	if finished > 0 {
+		stats.AvgInStock = inStock / time.Duration(finished)
+	}
+
+	const week = 7 * 24 * time.Hour
+
+	// Observed period is at least a week to avoid inflating early estimates.
+	period := max(records[len(records)-1].Time.Sub(records[0].Time), week)
+	stats.DropsPerWeek = float64(len(drops)) / (float64(period) / float64(week))
+
+	return stats
+}
+
+// Forecast predicts up to n hourly windows after now in which the next drop
+// is most likely to start, based on the weekday and hour of past drops.
+func Forecast(records []Record, now time.Time, n int) []Window {
+	drops := Drops(records)
+	if len(drops) == 0 || n <= 0 {
+		return nil
+	}
+
+	var buckets [7 * 24]int
+
+	for _, d := range drops {
+		start := d.Start.UTC()
+		buckets[int(start.Weekday())*24+start.Hour()]++
+	}
+
+	var windows []Window
+
+	for i, count := range buckets {
+		if count == 0 {
+			continue
+		}
+
+		start := nextOccurrence(now.UTC(), time.Weekday(i/24), i%24)
+
+		windows = append(windows, Window{
+			Start:      start,
+			End:        start.Add(time.Hour),
+			Likelihood: float64(count) / float64(len(drops)),
+		})
+	}
+
+	slices.SortFunc(windows, func(a, b Window) int {
+		if c := cmp.Compare(b.Likelihood, a.Likelihood); c != 0 {
+			return c
+		}
+
+		return a.Start.Compare(b.Start)
+	})
+
+	if len(windows) > n {
+		windows = windows[:n]
+	}
+
+	return windows
+}
+
+// nextOccurrence returns the start of the next weekday hour that ends after now.
+func nextOccurrence(now time.Time, day time.Weekday, hour int) time.Time {
+	start := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC).
+		AddDate(0, 0, (int(day)-int(now.Weekday())+7)%7)
+
+	if !start.Add(time.Hour).After(now) {
+		start = start.AddDate(0, 0, 7)
+	}
+
+	return start
+}
+
+func maxIndex(counts []int) int {
+	var idx int
+
+	for i, c := range counts {
+		if c > counts[idx] {
+			idx = i
+		}
+	}
+
+	return idx
+}
@@ -0,0 +1,217 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// at returns 2025-02-<day> hh:mm UTC, the 2nd is a Sunday.
+func at(day, hour, minute int) time.Time {
+	return time.Date(2025, 2, day, hour, minute, 0, 0, time.UTC)
+}
+
+func record(retailer string, stock int, t time.Time) Record {
+	return Record{SKU: "1147625", Retailer: retailer, Stock: stock, Time: t}
+}
+
+func TestDrops(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []Record
+		want    []Drop
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:    "single in stock",
+			records: []Record{record("Inet", 2, at(3, 9, 0))},
+			want:    []Drop{{Start: at(3, 9, 0)}},
+		},
+		{
+			name:    "single sold out",
+			records: []Record{record("Inet", 0, at(3, 9, 0))},
+		},
+		{
+			name: "in out in",
+			records: []Record{
+				record("Inet", 2, at(3, 9, 0)),
+				record("Inet", 0, at(3, 9, 30)),
+				record("Inet", 1, at(4, 14, 0)),
+			},
+			want: []Drop{
+				{Start: at(3, 9, 0), End: at(3, 9, 30)},
+				{Start: at(4, 14, 0)},
+			},
+		},
+		{
+			name: "stock changes within a drop",
+			records: []Record{
+				record("Inet", 5, at(3, 9, 0)),
+				record("Inet", 2, at(3, 9, 10)),
+				record("Inet", 0, at(3, 9, 20)),
+			},
+			want: []Drop{{Start: at(3, 9, 0), End: at(3, 9, 20)}},
+		},
+		{
+			// The drop lasts until the last retailer sells out.
+			name: "overlapping retailers",
+			records: []Record{
+				record("Inet", 1, at(3, 9, 0)),
+				record("Komplett", 3, at(3, 9, 5)),
+				record("Inet", 0, at(3, 9, 10)),
+				record("Komplett", 0, at(3, 9, 40)),
+			},
+			want: []Drop{{Start: at(3, 9, 0), End: at(3, 9, 40)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Drops(tt.records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Drops() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []Record
+		loc     *time.Location
+		want    Stats
+	}{
+		{
+			name: "empty",
+			want: Stats{SKU: "1147625"},
+		},
+		{
+			// Sunday 23:00 UTC is Monday 01:00 two hours east.
+			name:    "local time",
+			records: []Record{record("Inet", 1, at(2, 23, 0))},
+			loc:     time.FixedZone("EET", 2*60*60),
+			want: Stats{
+				SKU:           "1147625",
+				Drops:         1,
+				DropsPerWeek:  1,
+				TypicalHour:   1,
+				TypicalDay:    time.Monday,
+				LastDropStart: at(2, 23, 0),
+			},
+		},
+		{
+			name:    "single record",
+			records: []Record{record("Inet", 1, at(3, 9, 0))},
+			want: Stats{
+				SKU:           "1147625",
+				Drops:         1,
+				DropsPerWeek:  1,
+				TypicalHour:   9,
+				TypicalDay:    time.Monday,
+				LastDropStart: at(3, 9, 0),
+			},
+		},
+		{
+			name: "two weeks",
+			records: []Record{
+				record("Inet", 1, at(3, 9, 0)),
+				record("Inet", 0, at(3, 9, 30)),
+				record("Inet", 1, at(10, 9, 0)),
+				record("Inet", 0, at(10, 10, 0)),
+				record("Inet", 1, at(15, 20, 0)),
+				record("Inet", 0, at(17, 9, 0)),
+			},
+			want: Stats{
+				SKU:           "1147625",
+				Drops:         3,
+				DropsPerWeek:  1.5,
+				TypicalHour:   9,
+				TypicalDay:    time.Monday,
+				AvgInStock:    (30*time.Minute + time.Hour + 37*time.Hour) / 3,
+				LastDropStart: at(15, 20, 0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+
+			if got := Compute("1147625", tt.records, loc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForecast(t *testing.T) {
+	// Drops on Sundays at 23:00 and Mondays at 00:00 UTC.
+	records := []Record{
+		record("Inet", 1, at(2, 23, 10)),
+		record("Inet", 0, at(2, 23, 50)),
+		record("Inet", 1, at(9, 23, 5)),
+		record("Inet", 0, at(10, 0, 5)),
+		record("Inet", 1, at(17, 0, 15)),
+		record("Inet", 0, at(17, 0, 45)),
+	}
+
+	tests := []struct {
+		name    string
+		records []Record
+		now     time.Time
+		n       int
+		want    []Window
+	}{
+		{
+			name: "empty",
+			now:  at(20, 12, 0),
+			n:    3,
+		},
+		{
+			name:    "no windows requested",
+			records: records,
+			now:     at(20, 12, 0),
+		},
+		{
+			name:    "across midnight",
+			records: records,
+			now:     at(22, 12, 0),
+			n:       3,
+			want: []Window{
+				{Start: at(23, 23, 0), End: at(24, 0, 0), Likelihood: 2.0 / 3},
+				{Start: at(24, 0, 0), End: at(24, 1, 0), Likelihood: 1.0 / 3},
+			},
+		},
+		{
+			name:    "during window",
+			records: records,
+			now:     at(23, 23, 30),
+			n:       1,
+			want:    []Window{{Start: at(23, 23, 0), End: at(24, 0, 0), Likelihood: 2.0 / 3}},
+		},
+		{
+			// The Sunday window passed, the next one is a week later.
+			name:    "after window",
+			records: records,
+			now:     at(24, 0, 30),
+			n:       2,
+			want: []Window{
+				{Start: at(30, 23, 0), End: at(31, 0, 0), Likelihood: 2.0 / 3},
+				{Start: at(24, 0, 0), End: at(24, 1, 0), Likelihood: 1.0 / 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Forecast(tt.records, tt.now, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Forecast() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
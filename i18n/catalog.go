@@ -0,0 +1,418 @@
+package i18n
+
+var catalog = map[Lang]map[string]string{
+	English: {
+		"language_name":      "English",
+		"welcome":            "Welcome! Use /monitor to track product availability.",
+		"select_products":    "Select products:",
+		"confirm_products":   "Confirm Products",
+		"selected_product":   "Selected product: %s",
+		"select_countries":   "Select countries:",
+		"confirm_countries":  "Confirm Countries",
+		"selected_country":   "Selected country: %s",
+		"monitoring_started": "Monitoring started for %s in %s. /unmonitor to stop",
+		"monitoring_stopped": "Monitoring stopped. Use /monitor to start again.",
+		"unknown_command":    "Unknown command. Use /monitor or /unmonitor.",
+		"admin_only":         "Only administrators can change the subscription of this chat.",
+		"usage_monitor":      "Usage: /monitor <product, ...>; <country, ...>. Available products: %s. Available countries: %s",
+		"usage_product":      "Usage: %s <product>. Available products: %s",
+
+		"history_empty":     "No stock history recorded for %s yet.",
+		"history_title":     "Stock history for %s:",
+		"history_record":    "%s %s, %s: %d in stock",
+		"forecast_title":    "Restock forecast for %s:",
+		"forecast_no_drops": "%s: no drops recorded yet.",
+		"forecast_stats":    "%s: %.1f drops/week, usually %s around %02d:00 %s, in stock for %s on average.",
+
+		"retailers_all":  "all",
+		"retailers_none": "none",
+		"retailers":      "Notified retailers: %s\nIgnored retailers: %s\n\nUse /allow <retailer, ...> or /deny <retailer, ...> to change, without arguments to reset.",
+
+		"maxprice_usage": "Usage: /maxprice <product> <price> [currency], omit the price to remove the limit. The currency is required if your countries use several.",
+		"maxprice_none":  "No price limits set. Use /maxprice <product> <price> to only get notified about cheaper offers.",
+		"maxprice_title": "Price limits:",
+
+		"language_set":   "Language set to %s.",
+		"language_usage": "Usage: /language <code>. Available languages: %s",
+
+		"channel_enabled":  "%s notifications enabled.",
+		"channel_disabled": "%s notifications disabled.",
+		"channel_usage":    "Usage: %s <webhook URL>, or %s off to disable.",
+
+		"email_usage":          "Usage: /email <address>, or /email off to disable.",
+		"email_sent":           "A verification code was sent to %s. Reply with /verify <code> to enable email notifications.",
+		"email_verified":       "Email notifications enabled for %s.",
+		"email_invalid_code":   "Invalid verification code.",
+		"email_code_expired":   "The verification code expired. Request a new one with /email <address>.",
+		"email_disabled":       "Email notifications disabled.",
+		"email_unavailable":    "Email notifications are not available.",
+		"email_verify_subject": "RTX Sniper verification code",
+		"email_verify_body":    "Your RTX Sniper verification code is %s.",
+		"email_cooldown":       "Please wait a few minutes before requesting another verification code.",
+		"email_failed":         "Could not send the verification email to %s, check the address and try again.",
+
+		"subscription_expiring": "Your monitoring of %s expires on %s. Still interested? Send /renew to keep it.",
+		"subscription_renewed":  "Monitoring renewed until %s.",
+		"subscription_none":     "There is nothing to renew. Use /monitor to start monitoring.",
+		"not_offered":           "Not available: %s. Use /monitor to choose other products or countries.",
+		"not_offered_some":      "Not sold there and not monitored: %s.",
+
+		"notify_available":    "%s is now available in %s!",
+		"notify_stock":        "%d in stock",
+		"notify_in_stock":     "in stock",
+		"notify_unsubscribed": "Unsubscribed, use /monitor to subscribe again.",
+
+		"weekday_monday":    "Monday",
+		"weekday_tuesday":   "Tuesday",
+		"weekday_wednesday": "Wednesday",
+		"weekday_thursday":  "Thursday",
+		"weekday_friday":    "Friday",
+		"weekday_saturday":  "Saturday",
+		"weekday_sunday":    "Sunday",
+	},
+	Swedish: {
+		"language_name":      "Svenska",
+		"welcome":            "Välkommen! Använd /monitor för att bevaka tillgänglighet.",
+		"select_products":    "Välj produkter:",
+		"confirm_products":   "Bekräfta produkter",
+		"selected_product":   "Vald produkt: %s",
+		"select_countries":   "Välj länder:",
+		"confirm_countries":  "Bekräfta länder",
+		"selected_country":   "Valt land: %s",
+		"monitoring_started": "Bevakning startad för %s i %s. /unmonitor för att avsluta",
+		"monitoring_stopped": "Bevakningen avslutad. Använd /monitor för att starta igen.",
+		"unknown_command":    "Okänt kommando. Använd /monitor eller /unmonitor.",
+		"admin_only":         "Endast administratörer kan ändra bevakningen för den här chatten.",
+		"usage_monitor":      "Användning: /monitor <produkt, ...>; <land, ...>. Tillgängliga produkter: %s. Tillgängliga länder: %s",
+		"usage_product":      "Användning: %s <produkt>. Tillgängliga produkter: %s",
+
+		"history_empty":     "Ingen lagerhistorik har registrerats för %s ännu.",
+		"history_title":     "Lagerhistorik för %s:",
+		"history_record":    "%s %s, %s: %d i lager",
+		"forecast_title":    "Prognos för påfyllning av %s:",
+		"forecast_no_drops": "%s: inga släpp registrerade ännu.",
+		"forecast_stats":    "%s: %.1f släpp/vecka, oftast %s runt %02d:00 %s, i lager i %s i genomsnitt.",
+
+		"retailers_all":  "alla",
+		"retailers_none": "inga",
+		"retailers":      "Bevakade återförsäljare: %s\nIgnorerade återförsäljare: %s\n\nAnvänd /allow <återförsäljare, ...> eller /deny <återförsäljare, ...> för att ändra, utan argument för att återställa.",
+
+		"maxprice_usage": "Användning: /maxprice <produkt> <pris> [valuta], utelämna priset för att ta bort gränsen. Valutan krävs om dina länder använder flera.",
+		"maxprice_none":  "Inga prisgränser satta. Använd /maxprice <produkt> <pris> för att bara få notiser om billigare erbjudanden.",
+		"maxprice_title": "Prisgränser:",
+
+		"language_set":   "Språket är nu %s.",
+		"language_usage": "Användning: /language <kod>. Tillgängliga språk: %s",
+
+		"channel_enabled":  "%s-notiser aktiverade.",
+		"channel_disabled": "%s-notiser avaktiverade.",
+		"channel_usage":    "Användning: %s <webhook-URL>, eller %s off för att avaktivera.",
+
+		"email_usage":          "Användning: /email <adress>, eller /email off för att avaktivera.",
+		"email_sent":           "En verifieringskod har skickats till %s. Svara med /verify <kod> för att aktivera e-postnotiser.",
+		"email_verified":       "E-postnotiser aktiverade för %s.",
+		"email_invalid_code":   "Ogiltig verifieringskod.",
+		"email_code_expired":   "Verifieringskoden har gått ut. Begär en ny med /email <adress>.",
+		"email_disabled":       "E-postnotiser avaktiverade.",
+		"email_unavailable":    "E-postnotiser är inte tillgängliga.",
+		"email_verify_subject": "Verifieringskod för RTX Sniper",
+		"email_verify_body":    "Din verifieringskod för RTX Sniper är %s.",
+		"email_cooldown":       "Vänta några minuter innan du begär en ny verifieringskod.",
+		"email_failed":         "Verifieringsmejlet kunde inte skickas till %s, kontrollera adressen och försök igen.",
+
+		"subscription_expiring": "Din bevakning av %s upphör %s. Fortfarande intresserad? Skicka /renew för att behålla den.",
+		"subscription_renewed":  "Bevakningen förnyad till %s.",
+		"subscription_none":     "Det finns inget att förnya. Använd /monitor för att starta bevakning.",
+		"not_offered":           "Inte tillgänglig: %s. Använd /monitor för att välja andra produkter eller länder.",
+		"not_offered_some":      "Säljs inte där och bevakas inte: %s.",
+
+		"notify_available":    "%s finns nu tillgänglig i %s!",
+		"notify_stock":        "%d i lager",
+		"notify_in_stock":     "i lager",
+		"notify_unsubscribed": "Bevakningen avslutad, använd /monitor för att bevaka igen.",
+
+		"weekday_monday":    "måndag",
+		"weekday_tuesday":   "tisdag",
+		"weekday_wednesday": "onsdag",
+		"weekday_thursday":  "torsdag",
+		"weekday_friday":    "fredag",
+		"weekday_saturday":  "lördag",
+		"weekday_sunday":    "söndag",
+	},
+	Danish: {
+		"language_name":      "Dansk",
+		"welcome":            "Velkommen! Brug /monitor for at overvåge tilgængelighed.",
+		"select_products":    "Vælg produkter:",
+		"confirm_products":   "Bekræft produkter",
+		"selected_product":   "Valgt produkt: %s",
+		"select_countries":   "Vælg lande:",
+		"confirm_countries":  "Bekræft lande",
+		"selected_country":   "Valgt land: %s",
+		"monitoring_started": "Overvågning startet for %s i %s. /unmonitor for at stoppe",
+		"monitoring_stopped": "Overvågning stoppet. Brug /monitor for at starte igen.",
+		"unknown_command":    "Ukendt kommando. Brug /monitor eller /unmonitor.",
+		"admin_only":         "Kun administratorer kan ændre overvågningen for denne chat.",
+		"usage_monitor":      "Brug: /monitor <produkt, ...>; <land, ...>. Tilgængelige produkter: %s. Tilgængelige lande: %s",
+		"usage_product":      "Brug: %s <produkt>. Tilgængelige produkter: %s",
+
+		"history_empty":     "Der er endnu ikke registreret lagerhistorik for %s.",
+		"history_title":     "Lagerhistorik for %s:",
+		"history_record":    "%s %s, %s: %d på lager",
+		"forecast_title":    "Prognose for genopfyldning af %s:",
+		"forecast_no_drops": "%s: ingen drops registreret endnu.",
+		"forecast_stats":    "%s: %.1f drops/uge, typisk %s omkring kl. %02d:00 %s, på lager i %s i gennemsnit.",
+
+		"retailers_all":  "alle",
+		"retailers_none": "ingen",
+		"retailers":      "Overvågede forhandlere: %s\nIgnorerede forhandlere: %s\n\nBrug /allow <forhandler, ...> eller /deny <forhandler, ...> for at ændre, uden argumenter for at nulstille.",
+
+		"maxprice_usage": "Brug: /maxprice <produkt> <pris> [valuta], udelad prisen for at fjerne grænsen. Valutaen er påkrævet, hvis dine lande bruger flere.",
+		"maxprice_none":  "Ingen prisgrænser sat. Brug /maxprice <produkt> <pris> for kun at få besked om billigere tilbud.",
+		"maxprice_title": "Prisgrænser:",
+
+		"language_set":   "Sproget er sat til %s.",
+		"language_usage": "Brug: /language <kode>. Tilgængelige sprog: %s",
+
+		"channel_enabled":  "%s-notifikationer aktiveret.",
+		"channel_disabled": "%s-notifikationer deaktiveret.",
+		"channel_usage":    "Brug: %s <webhook-URL>, eller %s off for at deaktivere.",
+
+		"email_usage":          "Brug: /email <adresse>, eller /email off for at deaktivere.",
+		"email_sent":           "En bekræftelseskode er sendt til %s. Svar med /verify <kode> for at aktivere e-mailnotifikationer.",
+		"email_verified":       "E-mailnotifikationer aktiveret for %s.",
+		"email_invalid_code":   "Ugyldig bekræftelseskode.",
+		"email_code_expired":   "Bekræftelseskoden er udløbet. Anmod om en ny med /email <adresse>.",
+		"email_disabled":       "E-mailnotifikationer deaktiveret.",
+		"email_unavailable":    "E-mailnotifikationer er ikke tilgængelige.",
+		"email_verify_subject": "Bekræftelseskode til RTX Sniper",
+		"email_verify_body":    "Din bekræftelseskode til RTX Sniper er %s.",
+		"email_cooldown":       "Vent et par minutter, før du beder om en ny bekræftelseskode.",
+		"email_failed":         "Bekræftelsesmailen kunne ikke sendes til %s, tjek adressen og prøv igen.",
+
+		"subscription_expiring": "Din overvågning af %s udløber %s. Stadig interesseret? Send /renew for at beholde den.",
+		"subscription_renewed":  "Overvågning fornyet til %s.",
+		"subscription_none":     "Der er intet at forny. Brug /monitor for at starte overvågning.",
+		"not_offered":           "Ikke tilgængelig: %s. Brug /monitor for at vælge andre produkter eller lande.",
+		"not_offered_some":      "Sælges ikke der og overvåges ikke: %s.",
+
+		"notify_available":    "%s er nu tilgængelig i %s!",
+		"notify_stock":        "%d på lager",
+		"notify_in_stock":     "på lager",
+		"notify_unsubscribed": "Overvågning stoppet, brug /monitor for at overvåge igen.",
+
+		"weekday_monday":    "mandag",
+		"weekday_tuesday":   "tirsdag",
+		"weekday_wednesday": "onsdag",
+		"weekday_thursday":  "torsdag",
+		"weekday_friday":    "fredag",
+		"weekday_saturday":  "lørdag",
+		"weekday_sunday":    "søndag",
+	},
+	Finnish: {
+		"language_name":      "Suomi",
+		"welcome":            "Tervetuloa! Käytä komentoa /monitor seurataksesi saatavuutta.",
+		"select_products":    "Valitse tuotteet:",
+		"confirm_products":   "Vahvista tuotteet",
+		"selected_product":   "Valittu tuote: %s",
+		"select_countries":   "Valitse maat:",
+		"confirm_countries":  "Vahvista maat",
+		"selected_country":   "Valittu maa: %s",
+		"monitoring_started": "Seuranta aloitettu: %s, %s. /unmonitor lopettaa",
+		"monitoring_stopped": "Seuranta lopetettu. Käytä komentoa /monitor aloittaaksesi uudelleen.",
+		"unknown_command":    "Tuntematon komento. Käytä komentoa /monitor tai /unmonitor.",
+		"admin_only":         "Vain ylläpitäjät voivat muuttaa tämän keskustelun seurantaa.",
+		"usage_monitor":      "Käyttö: /monitor <tuote, ...>; <maa, ...>. Saatavilla olevat tuotteet: %s. Saatavilla olevat maat: %s",
+		"usage_product":      "Käyttö: %s <tuote>. Saatavilla olevat tuotteet: %s",
+
+		"history_empty":     "Tuotteelle %s ei ole vielä varastohistoriaa.",
+		"history_title":     "Varastohistoria: %s",
+		"history_record":    "%s %s, %s: %d varastossa",
+		"forecast_title":    "Saatavuusennuste: %s",
+		"forecast_no_drops": "%s: ei vielä havaittuja saapumisia.",
+		"forecast_stats":    "%s: %.1f saapumista/viikko, yleensä %s noin klo %02d:00 %s, varastossa keskimäärin %s.",
+
+		"retailers_all":  "kaikki",
+		"retailers_none": "ei yhtään",
+		"retailers":      "Seuratut jälleenmyyjät: %s\nOhitetut jälleenmyyjät: %s\n\nKäytä /allow <jälleenmyyjä, ...> tai /deny <jälleenmyyjä, ...> muuttaaksesi, ilman argumentteja palauttaaksesi.",
+
+		"maxprice_usage": "Käyttö: /maxprice <tuote> <hinta> [valuutta], jätä hinta pois poistaaksesi rajan. Valuutta vaaditaan, jos maasi käyttävät useita.",
+		"maxprice_none":  "Hintarajoja ei ole asetettu. Käytä /maxprice <tuote> <hinta> saadaksesi ilmoitukset vain edullisemmista tarjouksista.",
+		"maxprice_title": "Hintarajat:",
+
+		"language_set":   "Kieleksi asetettu %s.",
+		"language_usage": "Käyttö: /language <koodi>. Saatavilla olevat kielet: %s",
+
+		"channel_enabled":  "%s-ilmoitukset otettu käyttöön.",
+		"channel_disabled": "%s-ilmoitukset poistettu käytöstä.",
+		"channel_usage":    "Käyttö: %s <webhook-URL>, tai %s off poistaaksesi käytöstä.",
+
+		"email_usage":          "Käyttö: /email <osoite>, tai /email off poistaaksesi käytöstä.",
+		"email_sent":           "Vahvistuskoodi lähetettiin osoitteeseen %s. Vastaa /verify <koodi> ottaaksesi sähköposti-ilmoitukset käyttöön.",
+		"email_verified":       "Sähköposti-ilmoitukset otettu käyttöön osoitteelle %s.",
+		"email_invalid_code":   "Virheellinen vahvistuskoodi.",
+		"email_code_expired":   "Vahvistuskoodi on vanhentunut. Pyydä uusi komennolla /email <osoite>.",
+		"email_disabled":       "Sähköposti-ilmoitukset poistettu käytöstä.",
+		"email_unavailable":    "Sähköposti-ilmoitukset eivät ole käytettävissä.",
+		"email_verify_subject": "RTX Sniper -vahvistuskoodi",
+		"email_verify_body":    "RTX Sniper -vahvistuskoodisi on %s.",
+		"email_cooldown":       "Odota muutama minuutti ennen kuin pyydät uuden vahvistuskoodin.",
+		"email_failed":         "Vahvistusviestiä ei voitu lähettää osoitteeseen %s, tarkista osoite ja yritä uudelleen.",
+
+		"subscription_expiring": "Seurantasi (%s) päättyy %s. Kiinnostaako yhä? Lähetä /renew jatkaaksesi.",
+		"subscription_renewed":  "Seuranta uusittu %s asti.",
+		"subscription_none":     "Ei uusittavaa. Käytä komentoa /monitor aloittaaksesi seurannan.",
+		"not_offered":           "Ei saatavilla: %s. Käytä komentoa /monitor valitaksesi muita tuotteita tai maita.",
+		"not_offered_some":      "Ei myynnissä eikä seurannassa: %s.",
+
+		"notify_available":    "%s on nyt saatavilla maassa %s!",
+		"notify_stock":        "%d varastossa",
+		"notify_in_stock":     "varastossa",
+		"notify_unsubscribed": "Seuranta lopetettu, käytä komentoa /monitor seurataksesi uudelleen.",
+
+		"weekday_monday":    "maanantaina",
+		"weekday_tuesday":   "tiistaina",
+		"weekday_wednesday": "keskiviikkona",
+		"weekday_thursday":  "torstaina",
+		"weekday_friday":    "perjantaina",
+		"weekday_saturday":  "lauantaina",
+		"weekday_sunday":    "sunnuntaina",
+	},
+	German: {
+		"language_name":      "Deutsch",
+		"welcome":            "Willkommen! Verwende /monitor, um die Verfügbarkeit zu überwachen.",
+		"select_products":    "Produkte auswählen:",
+		"confirm_products":   "Produkte bestätigen",
+		"selected_product":   "Ausgewähltes Produkt: %s",
+		"select_countries":   "Länder auswählen:",
+		"confirm_countries":  "Länder bestätigen",
+		"selected_country":   "Ausgewähltes Land: %s",
+		"monitoring_started": "Überwachung für %s in %s gestartet. /unmonitor zum Beenden",
+		"monitoring_stopped": "Überwachung beendet. Verwende /monitor, um erneut zu starten.",
+		"unknown_command":    "Unbekannter Befehl. Verwende /monitor oder /unmonitor.",
+		"admin_only":         "Nur Administratoren können die Überwachung dieses Chats ändern.",
+		"usage_monitor":      "Verwendung: /monitor <Produkt, ...>; <Land, ...>. Verfügbare Produkte: %s. Verfügbare Länder: %s",
+		"usage_product":      "Verwendung: %s <Produkt>. Verfügbare Produkte: %s",
+
+		"history_empty":     "Für %s wurde noch kein Lagerverlauf aufgezeichnet.",
+		"history_title":     "Lagerverlauf für %s:",
+		"history_record":    "%s %s, %s: %d auf Lager",
+		"forecast_title":    "Prognose der Wiederauffüllung für %s:",
+		"forecast_no_drops": "%s: noch keine Drops aufgezeichnet.",
+		"forecast_stats":    "%s: %.1f Drops/Woche, meist %s gegen %02d:00 %s, durchschnittlich %s auf Lager.",
+
+		"retailers_all":  "alle",
+		"retailers_none": "keine",
+		"retailers":      "Überwachte Händler: %s\nIgnorierte Händler: %s\n\nVerwende /allow <Händler, ...> oder /deny <Händler, ...> zum Ändern, ohne Argumente zum Zurücksetzen.",
+
+		"maxprice_usage": "Verwendung: /maxprice <Produkt> <Preis> [Währung], ohne Preis wird das Limit entfernt. Die Währung ist nötig, wenn deine Länder mehrere verwenden.",
+		"maxprice_none":  "Keine Preislimits gesetzt. Verwende /maxprice <Produkt> <Preis>, um nur über günstigere Angebote benachrichtigt zu werden.",
+		"maxprice_title": "Preislimits:",
+
+		"language_set":   "Sprache auf %s gesetzt.",
+		"language_usage": "Verwendung: /language <Code>. Verfügbare Sprachen: %s",
+
+		"channel_enabled":  "%s-Benachrichtigungen aktiviert.",
+		"channel_disabled": "%s-Benachrichtigungen deaktiviert.",
+		"channel_usage":    "Verwendung: %s <Webhook-URL>, oder %s off zum Deaktivieren.",
+
+		"email_usage":          "Verwendung: /email <Adresse>, oder /email off zum Deaktivieren.",
+		"email_sent":           "Ein Bestätigungscode wurde an %s gesendet. Antworte mit /verify <Code>, um E-Mail-Benachrichtigungen zu aktivieren.",
+		"email_verified":       "E-Mail-Benachrichtigungen für %s aktiviert.",
+		"email_invalid_code":   "Ungültiger Bestätigungscode.",
+		"email_code_expired":   "Der Bestätigungscode ist abgelaufen. Fordere mit /email <Adresse> einen neuen an.",
+		"email_disabled":       "E-Mail-Benachrichtigungen deaktiviert.",
+		"email_unavailable":    "E-Mail-Benachrichtigungen sind nicht verfügbar.",
+		"email_verify_subject": "RTX Sniper Bestätigungscode",
+		"email_verify_body":    "Dein RTX Sniper Bestätigungscode lautet %s.",
+		"email_cooldown":       "Bitte warte ein paar Minuten, bevor du einen neuen Bestätigungscode anforderst.",
+		"email_failed":         "Die Bestätigungs-E-Mail an %s konnte nicht gesendet werden, prüfe die Adresse und versuche es erneut.",
+
+		"subscription_expiring": "Deine Überwachung von %s endet am %s. Noch interessiert? Sende /renew, um sie zu behalten.",
+		"subscription_renewed":  "Überwachung verlängert bis %s.",
+		"subscription_none":     "Es gibt nichts zu verlängern. Verwende /monitor, um die Überwachung zu starten.",
+		"not_offered":           "Nicht verfügbar: %s. Verwende /monitor, um andere Produkte oder Länder zu wählen.",
+		"not_offered_some":      "Dort nicht erhältlich und nicht überwacht: %s.",
+
+		"notify_available":    "%s ist jetzt in %s verfügbar!",
+		"notify_stock":        "%d auf Lager",
+		"notify_in_stock":     "auf Lager",
+		"notify_unsubscribed": "Überwachung beendet, verwende /monitor, um erneut zu überwachen.",
+
+		"weekday_monday":    "montags",
+		"weekday_tuesday":   "dienstags",
+		"weekday_wednesday": "mittwochs",
+		"weekday_thursday":  "donnerstags",
+		"weekday_friday":    "freitags",
+		"weekday_saturday":  "samstags",
+		"weekday_sunday":    "sonntags",
+	},
+	Dutch: {
+		"language_name":      "Nederlands",
+		"welcome":            "Welkom! Gebruik /monitor om de beschikbaarheid te volgen.",
+		"select_products":    "Kies producten:",
+		"confirm_products":   "Producten bevestigen",
+		"selected_product":   "Gekozen product: %s",
+		"select_countries":   "Kies landen:",
+		"confirm_countries":  "Landen bevestigen",
+		"selected_country":   "Gekozen land: %s",
+		"monitoring_started": "Volgen gestart voor %s in %s. /unmonitor om te stoppen",
+		"monitoring_stopped": "Volgen gestopt. Gebruik /monitor om opnieuw te beginnen.",
+		"unknown_command":    "Onbekend commando. Gebruik /monitor of /unmonitor.",
+		"admin_only":         "Alleen beheerders kunnen het volgen voor deze chat wijzigen.",
+		"usage_monitor":      "Gebruik: /monitor <product, ...>; <land, ...>. Beschikbare producten: %s. Beschikbare landen: %s",
+		"usage_product":      "Gebruik: %s <product>. Beschikbare producten: %s",
+
+		"history_empty":     "Nog geen voorraadgeschiedenis vastgelegd voor %s.",
+		"history_title":     "Voorraadgeschiedenis voor %s:",
+		"history_record":    "%s %s, %s: %d op voorraad",
+		"forecast_title":    "Voorraadvoorspelling voor %s:",
+		"forecast_no_drops": "%s: nog geen drops vastgelegd.",
+		"forecast_stats":    "%s: %.1f drops/week, meestal op %s rond %02d:00 %s, gemiddeld %s op voorraad.",
+
+		"retailers_all":  "alle",
+		"retailers_none": "geen",
+		"retailers":      "Gevolgde winkels: %s\nGenegeerde winkels: %s\n\nGebruik /allow <winkel, ...> of /deny <winkel, ...> om te wijzigen, zonder argumenten om te resetten.",
+
+		"maxprice_usage": "Gebruik: /maxprice <product> <prijs> [valuta], laat de prijs weg om de limiet te verwijderen. De valuta is verplicht als je landen er meerdere gebruiken.",
+		"maxprice_none":  "Geen prijslimieten ingesteld. Gebruik /maxprice <product> <prijs> om alleen meldingen over goedkopere aanbiedingen te krijgen.",
+		"maxprice_title": "Prijslimieten:",
+
+		"language_set":   "Taal ingesteld op %s.",
+		"language_usage": "Gebruik: /language <code>. Beschikbare talen: %s",
+
+		"channel_enabled":  "%s-meldingen ingeschakeld.",
+		"channel_disabled": "%s-meldingen uitgeschakeld.",
+		"channel_usage":    "Gebruik: %s <webhook-URL>, of %s off om uit te schakelen.",
+
+		"email_usage":          "Gebruik: /email <adres>, of /email off om uit te schakelen.",
+		"email_sent":           "Er is een verificatiecode naar %s gestuurd. Antwoord met /verify <code> om e-mailmeldingen in te schakelen.",
+		"email_verified":       "E-mailmeldingen ingeschakeld voor %s.",
+		"email_invalid_code":   "Ongeldige verificatiecode.",
+		"email_code_expired":   "De verificatiecode is verlopen. Vraag een nieuwe aan met /email <adres>.",
+		"email_disabled":       "E-mailmeldingen uitgeschakeld.",
+		"email_unavailable":    "E-mailmeldingen zijn niet beschikbaar.",
+		"email_verify_subject": "RTX Sniper verificatiecode",
+		"email_verify_body":    "Je RTX Sniper verificatiecode is %s.",
+		"email_cooldown":       "Wacht een paar minuten voordat je een nieuwe verificatiecode aanvraagt.",
+		"email_failed":         "De verificatiemail kon niet naar %s worden verzonden, controleer het adres en probeer het opnieuw.",
+
+		"subscription_expiring": "Je volgen van %s verloopt op %s. Nog steeds geïnteresseerd? Stuur /renew om het te behouden.",
+		"subscription_renewed":  "Volgen verlengd tot %s.",
+		"subscription_none":     "Er is niets om te verlengen. Gebruik /monitor om te beginnen met volgen.",
+		"not_offered":           "Niet beschikbaar: %s. Gebruik /monitor om andere producten of landen te kiezen.",
+		"not_offered_some":      "Daar niet verkrijgbaar en niet gevolgd: %s.",
+
+		"notify_available":    "%s is nu beschikbaar in %s!",
+		"notify_stock":        "%d op voorraad",
+		"notify_in_stock":     "op voorraad",
+		"notify_unsubscribed": "Volgen gestopt, gebruik /monitor om opnieuw te volgen.",
+
+		"weekday_monday":    "maandag",
+		"weekday_tuesday":   "dinsdag",
+		"weekday_wednesday": "woensdag",
+		"weekday_thursday":  "donderdag",
+		"weekday_friday":    "vrijdag",
+		"weekday_saturday":  "zaterdag",
+		"weekday_sunday":    "zondag",
+	},
+}
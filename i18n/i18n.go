@@ -0,0 +1,84 @@
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Lang string
+
+var (
+	English = Lang("en")
+	Swedish = Lang("sv")
+	Danish  = Lang("da")
+	Finnish = Lang("fi")
+	German  = Lang("de")
+	Dutch   = Lang("nl")
+)
+
+// Parse returns the catalog language of an IETF language tag such as "sv" or
+// "de-AT", falling back to English for unsupported languages.
+func Parse(code string) Lang {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	base, _, _ = strings.Cut(base, "_")
+
+	if _, ok := catalog[Lang(base)]; ok {
+		return Lang(base)
+	}
+
+	return English
+}
+
+// Supported reports whether the code names a language of the catalog.
+func Supported(code string) bool {
+	_, ok := catalog[Lang(strings.ToLower(strings.TrimSpace(code)))]
+	return ok
+}
+
+// Languages returns all catalog languages ordered by code.
+func Languages() []Lang {
+	langs := make([]Lang, 0, len(catalog))
+
+	for l := range catalog {
+		langs = append(langs, l)
+	}
+
+	slices.Sort(langs)
+
+	return langs
+}
+
+// T returns the message of the key in the language formatted with args.
+// Missing translations fall back to English, and to the key itself.
+func T(l Lang, key string, args ...any) string {
+	msg, ok := catalog[l][key]
+	if !ok {
+		msg, ok = catalog[English][key]
+	}
+
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+
+	return fmt.Sprintf(msg, args...)
+}
+
+// Weekday returns the localized name of the weekday.
+func Weekday(l Lang, d time.Weekday) string {
+	return T(l, "weekday_"+strings.ToLower(d.String()))
+}
+
+// Name returns the native name of the language.
+func (l Lang) Name() string {
+	return T(l, "language_name")
+}
+
+func (l Lang) String() string {
+	return string(l)
+}
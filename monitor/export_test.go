@@ -0,0 +1,28 @@
+package monitor
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+)
+
+// CheckStock runs a single stock check of the product in the country.
+func (m *Monitor) CheckStock(ctx context.Context, product, country string) error {
+	return m.checkStock(ctx, sku{
+		prod:    nvidia.Product(product),
+		country: nvidia.Country(country),
+	})
+}
+
+// WatchedSKUs starts watching the requests and returns the codes of the
+// monitored SKUs.
+func (m *Monitor) WatchedSKUs(ctx context.Context) []string {
+	m.watchRequests(ctx)
+
+	m.activeSKUmu.Lock()
+	defer m.activeSKUmu.Unlock()
+
+	return slices.Sorted(maps.Keys(m.activeSKUs))
+}
@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+// RequestMigrations upgrade stored requests to the current version, see
+// storage.WithMigrations. Append new migrations, never change existing ones.
+var RequestMigrations = []storage.Migration{
+	migrateRequestChat,
+	migrateRequestMaxPrices,
+}
+
+// migrateRequestChat sets the chat of requests stored before groups and
+// channels were supported, which are private chats keyed by the chat ID.
+func migrateRequestChat(key string, item json.RawMessage) (json.RawMessage, error) {
+	var req map[string]json.RawMessage
+
+	if err := json.Unmarshal(item, &req); err != nil {
+		return nil, err
+	}
+
+	if _, ok := req["chatId"]; !ok {
+		if _, err := strconv.ParseInt(key, 10, 64); err != nil {
+			return nil, err
+		}
+
+		req["chatId"] = json.RawMessage(key)
+		req["chatType"] = json.RawMessage(`"private"`)
+	}
+
+	return json.Marshal(req)
+}
+
+// migrateRequestMaxPrices converts the price limits of products, which were
+// compared with offers in any currency, to limits in the currencies of the
+// requested countries. Limits of requests without such a country are dropped.
+func migrateRequestMaxPrices(_ string, item json.RawMessage) (json.RawMessage, error) {
+	var (
+		req    map[string]json.RawMessage
+		legacy struct {
+			Countries []string           `json:"countries"`
+			MaxPrices map[string]float64 `json:"maxPrices"`
+		}
+	)
+
+	if err := json.Unmarshal(item, &req); err != nil {
+		return nil, err
+	}
+
+	if _, ok := req["maxPrices"]; !ok {
+		return item, nil
+	}
+
+	if err := json.Unmarshal(item, &legacy); err != nil {
+		return nil, err
+	}
+
+	maxPrices := make(map[string]map[string]float64)
+
+	for prod, price := range legacy.MaxPrices {
+		for _, currency := range (Request{Countries: legacy.Countries}).Currencies() {
+			if maxPrices[prod] == nil {
+				maxPrices[prod] = make(map[string]float64)
+			}
+
+			maxPrices[prod][currency] = price
+		}
+	}
+
+	delete(req, "maxPrices")
+
+	if len(maxPrices) > 0 {
+		data, err := json.Marshal(maxPrices)
+		if err != nil {
+			return nil, err
+		}
+
+		req["maxPrices"] = data
+	}
+
+	return json.Marshal(req)
+}
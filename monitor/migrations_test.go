@@ -0,0 +1,199 @@
+package monitor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestRequestMigrations(t *testing.T) {
+	tests := []struct {
+		name string
+		// version of the stored items, nil for files written before
+		// versioning.
+		version *int
+		items   map[string]string
+		want    map[string]Request
+		wantErr string
+	}{
+		{
+			name: "unversioned",
+			items: map[string]string{
+				"123": `{"products":["RTX 5090 FE"],"countries":["Sweden"],"maxPrices":{"RTX 5090 FE":20000}}`,
+				"456": `{"products":["RTX 5080 FE"],"countries":["Narnia"],"maxPrices":{"RTX 5080 FE":9000}}`,
+			},
+			want: map[string]Request{
+				"123": {
+					Products:  []string{"RTX 5090 FE"},
+					Countries: []string{"Sweden"},
+					MaxPrices: map[string]map[string]float64{"RTX 5090 FE": {"SEK": 20000}},
+					ChatID:    123,
+					ChatType:  "private",
+				},
+				// Limits without a known currency are dropped.
+				"456": {
+					Products:  []string{"RTX 5080 FE"},
+					Countries: []string{"Narnia"},
+					ChatID:    456,
+					ChatType:  "private",
+				},
+			},
+		},
+		{
+			name:    "version 1",
+			version: ptr(1),
+			items: map[string]string{
+				"group": `{"products":["RTX 5090 FE"],"countries":["Germany","Sweden"],"maxPrices":{"RTX 5090 FE":2000},"chatId":-100,"chatType":"group","persistent":true}`,
+			},
+			want: map[string]Request{
+				"group": {
+					Products:   []string{"RTX 5090 FE"},
+					Countries:  []string{"Germany", "Sweden"},
+					MaxPrices:  map[string]map[string]float64{"RTX 5090 FE": {"EUR": 2000, "SEK": 2000}},
+					ChatID:     -100,
+					ChatType:   "group",
+					Persistent: true,
+				},
+			},
+		},
+		{
+			name:    "current version",
+			version: ptr(len(RequestMigrations)),
+			items: map[string]string{
+				"123": `{"products":["RTX 5090 FE"],"countries":["Sweden"],"maxPrices":{"RTX 5090 FE":{"SEK":20000}},"chatId":123,"chatType":"private"}`,
+			},
+			want: map[string]Request{
+				"123": {
+					Products:  []string{"RTX 5090 FE"},
+					Countries: []string{"Sweden"},
+					MaxPrices: map[string]map[string]float64{"RTX 5090 FE": {"SEK": 20000}},
+					ChatID:    123,
+					ChatType:  "private",
+				},
+			},
+		},
+		{
+			name:    "newer version",
+			version: ptr(len(RequestMigrations) + 1),
+			items: map[string]string{
+				"123": `{"products":["RTX 5090 FE"],"countries":["Sweden"],"chatId":123,"chatType":"private"}`,
+			},
+			wantErr: "newer than the supported version",
+		},
+	}
+
+	drivers := []struct {
+		name string
+		open func(t *testing.T, version *int, items map[string]string) (storage.Store[Request], error)
+	}{
+		{"json", openJSONRequests},
+		{"bolt", openBoltRequests},
+	}
+
+	for _, d := range drivers {
+		for _, tt := range tests {
+			t.Run(d.name+"/"+tt.name, func(t *testing.T) {
+				store, err := d.open(t, tt.version, tt.items)
+
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("open error = %v, want %q", err, tt.wantErr)
+					}
+
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("open error = %v", err)
+				}
+
+				defer store.Close()
+
+				got := make(map[string]Request)
+
+				for key, req := range store.All() {
+					got[key] = req
+				}
+
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("requests = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+// openJSONRequests writes the items to a JSON file like the version did and
+// opens it with the request migrations.
+func openJSONRequests(t *testing.T, version *int, items map[string]string) (storage.Store[Request], error) {
+	raw := make(map[string]json.RawMessage)
+
+	for key, item := range items {
+		raw[key] = json.RawMessage(item)
+	}
+
+	var file any = raw
+
+	if version != nil {
+		file = map[string]any{"version": *version, "items": raw}
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := filepath.Join(t.TempDir(), "db.json")
+
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return storage.Open[Request](name, storage.WithMigrations(RequestMigrations...))
+}
+
+// openBoltRequests stores the items in a bolt database with the version and
+// opens it with the request migrations.
+func openBoltRequests(t *testing.T, version *int, items map[string]string) (storage.Store[Request], error) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "sniper.db"), 0644, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	// Migrations of the old version leave the items as they are.
+	var migrations []storage.Migration
+
+	if version != nil {
+		for range *version {
+			migrations = append(migrations, func(_ string, item json.RawMessage) (json.RawMessage, error) {
+				return item, nil
+			})
+		}
+	}
+
+	old, err := storage.OpenBolt[json.RawMessage](db, "requests", storage.WithMigrations(migrations...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, item := range items {
+		if err := old.Add(key, json.RawMessage(item)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return storage.OpenBolt[Request](db, "requests", storage.WithMigrations(RequestMigrations...))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
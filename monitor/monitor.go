@@ -4,64 +4,246 @@ import (
 	"context"
 	"errors"
 	"log/slog"
-	"strconv"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/dyptan-io/rtx-sniper-bot/async"
+	"github.com/dyptan-io/rtx-sniper-bot/history"
 	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
 	"github.com/dyptan-io/rtx-sniper-bot/storage"
 )
 
 var ErrNotAvailable = errors.New("product not available")
 
+// IndexSKU indexes requests by the SKU codes of their products and countries.
+const IndexSKU = "sku"
+
 type (
 	Request struct {
 		Products  []string `json:"products"`
 		Countries []string `json:"countries"`
+		// Retailers limits notifications to the listed retailers, all if empty.
+		Retailers []string `json:"retailers,omitempty"`
+		// ExcludedRetailers are never notified about.
+		ExcludedRetailers []string `json:"excludedRetailers,omitempty"`
+		// MaxPrices maps product names to the highest accepted offer price by
+		// currency, e.g. {"RTX 5090 FE": {"SEK": 25000}}.
+		MaxPrices map[string]map[string]float64 `json:"maxPrices,omitempty"`
+		// Language is the code of the language of messages to the user.
+		Language string `json:"language,omitempty"`
+		// Channels maps additional notification channels, e.g. "discord", to
+		// their targets such as webhook URLs.
+		Channels map[string]string `json:"channels,omitempty"`
+		// PendingEmail awaits verification with EmailCode before it's added
+		// to Channels. The code is valid until EmailCodeExpires and for a few
+		// EmailAttempts only.
+		PendingEmail     string     `json:"pendingEmail,omitempty"`
+		EmailCode        string     `json:"emailCode,omitempty"`
+		EmailCodeExpires *time.Time `json:"emailCodeExpires,omitempty"`
+		EmailAttempts    int        `json:"emailAttempts,omitempty"`
+		// ChatID is the Telegram chat to notify, a private chat, a group or a
+		// channel, with ChatType being one of "private", "group",
+		// "supergroup" or "channel".
+		ChatID   int64  `json:"chatId,omitempty"`
+		ChatType string `json:"chatType,omitempty"`
+		// Persistent requests stay active after a notification and are
+		// notified once per restock.
+		Persistent bool `json:"persistent,omitempty"`
+		// ExpiryPrompted is set once the user was asked to renew the
+		// expiring request.
+		ExpiryPrompted bool `json:"expiryPrompted,omitempty"`
+	}
+
+	// RetailerFilter selects the offers to notify about.
+	RetailerFilter struct {
+		Retailers         []string
+		ExcludedRetailers []string
+		// ExcludedPartners and ExcludedStores match the partnerId and storeId
+		// of an offer, e.g. to skip the NVIDIA store itself.
+		ExcludedPartners []string
+		ExcludedStores   []string
 	}
 
 	Monitor struct {
-		store       *storage.Storage[Request]
-		scheduler   *async.Scheduler
-		pool        async.Pool
-		api         *nvidia.Client
-		notCh       chan<- Notification
-		activeSKUs  map[string]sku
-		activeSKUmu sync.Mutex
-		log         *slog.Logger
+		store        storage.Store[Request]
+		updateMu     sync.Mutex
+		scheduler    *async.Scheduler
+		pool         async.Pool
+		source       StockSource
+		outbox       Outbox
+		activeSKUs   map[string]sku
+		skuUsers     map[string]int
+		userSKUs     map[string]map[string]sku
+		activeSKUmu  sync.Mutex
+		history      *history.Store
+		fastInterval time.Duration
+		stocks       map[string]map[string]int
+		stocksMu     sync.Mutex
+		filter       RetailerFilter
+		onRestock    func(Notification)
+		restocked    map[string]bool
+		restockedMu  sync.Mutex
+		lifetime     time.Duration
+		onExpiring   func(userID string, req Request, expires time.Time)
+		log          *slog.Logger
 	}
 
+	Option func(*Monitor)
+
+	// StockSource returns the offers of products, e.g. the NVIDIA API or
+	// retailer pages. Products and countries are identified by name, e.g.
+	// "RTX 5090 FE" and "Sweden".
+	StockSource interface {
+		// Check returns the current offers of the product in the country.
+		Check(ctx context.Context, product, country string) ([]Offer, error)
+	}
+
+	// Catalog is implemented by stock sources knowing the products they
+	// offer, e.g. to reject unsupported subscriptions.
+	Catalog interface {
+		Offers(product, country string) bool
+	}
+
+	// StockSourceFunc adapts a function to the StockSource interface.
+	StockSourceFunc func(ctx context.Context, product, country string) ([]Offer, error)
+
+	// Outbox stores notifications for delivery without waiting for it.
+	Outbox interface {
+		Put(n Notification) error
+	}
+
+	// OutboxFunc adapts a function to the Outbox interface.
+	OutboxFunc func(n Notification) error
+
 	Notification struct {
-		UserID  int64
-		Message string
-		URLs    map[string]string
+		// ID is assigned by the Outbox.
+		ID       string
+		ChatID   int64
+		Language string
+		SKU      string
+		Product  string
+		Country  string
+		Offers   []Offer
+		Channels map[string]string
+		// Channel is the channel of the delivery, assigned by the Outbox.
+		Channel string
+		// Persistent notifications don't end the subscription.
+		Persistent bool
+		Time       time.Time
+	}
+
+	// Offer is a retailer offer of the available product.
+	Offer struct {
+		Title    string
+		Retailer string
+		// PartnerID and StoreID identify the seller in the NVIDIA API, see
+		// RetailerFilter.
+		PartnerID string
+		StoreID   string
+		Stock     int
+		Price     nvidia.Price
+		Currency  string
+		// Link prefers the direct purchase link over the retailer page.
+		Link string
+		// PageLink is the retailer page of the product, empty if unknown.
+		PageLink string
 	}
 
 	sku struct {
 		prod    nvidia.Product
 		country nvidia.Country
-		users   []int64
+	}
+
+	subscriber struct {
+		key    string
+		chatID int64
+		req    Request
 	}
 )
 
-func New(log *slog.Logger, store *storage.Storage[Request], sch *async.Scheduler, pool async.Pool, api *nvidia.Client, notCh chan<- Notification) *Monitor {
-	return &Monitor{
+// DefaultRetailerFilter excludes the NVIDIA partner and store, which only
+// list the product without selling it.
+var DefaultRetailerFilter = RetailerFilter{
+	ExcludedPartners: []string{"111"},
+	ExcludedStores:   []string{"9595"},
+}
+
+func New(log *slog.Logger, store storage.Store[Request], sch *async.Scheduler, pool async.Pool, source StockSource, outbox Outbox, opts ...Option) *Monitor {
+	m := Monitor{
 		store:      store,
 		scheduler:  sch,
 		pool:       pool,
-		api:        api,
-		notCh:      notCh,
+		source:     source,
+		outbox:     outbox,
 		activeSKUs: make(map[string]sku),
+		skuUsers:   make(map[string]int),
+		userSKUs:   make(map[string]map[string]sku),
+		stocks:     make(map[string]map[string]int),
+		filter:     DefaultRetailerFilter,
+		restocked:  make(map[string]bool),
 		log:        log,
 	}
+
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	return &m
+}
+
+// WithFastPoll additionally checks SKUs at the given interval while they are
+// inside a drop window predicted from the history. Requires WithHistory.
+func WithFastPoll(interval time.Duration) Option {
+	return func(m *Monitor) {
+		m.fastInterval = interval
+	}
+}
+
+// WithRetailerFilter replaces the DefaultRetailerFilter applied to all users.
+func WithRetailerFilter(f RetailerFilter) Option {
+	return func(m *Monitor) {
+		m.filter = f
+	}
+}
+
+// WithRestockHandler calls fn once per restock of a monitored SKU, i.e. when
+// it becomes available after not being available. The notification contains
+// all offers and has no ChatID.
+func WithRestockHandler(fn func(Notification)) Option {
+	return func(m *Monitor) {
+		m.onRestock = fn
+	}
+}
+
+// WithLifetime expires requests after the lifetime without any update. Users
+// monitoring products are passed to onExpiring once shortly before their
+// request expires, e.g. to ask them to Renew it.
+func WithLifetime(lifetime time.Duration, onExpiring func(userID string, req Request, expires time.Time)) Option {
+	return func(m *Monitor) {
+		m.lifetime = lifetime
+		m.onExpiring = onExpiring
+	}
+}
+
+// WithHistory enables recording of stock transitions to the history store.
+func WithHistory(h *history.Store) Option {
+	return func(m *Monitor) {
+		m.history = h
+	}
 }
 
 func (m *Monitor) Start(ctx context.Context, interval time.Duration, workers int) {
+	m.watchRequests(ctx)
+
 	m.scheduler.Schedule(ctx, interval, func(ctx context.Context) error {
-		m.updateActiveSKUs()
+		m.activeSKUmu.Lock()
+		skus := maps.Clone(m.activeSKUs)
+		m.activeSKUmu.Unlock()
 
-		numSKUs := len(m.activeSKUs)
+		numSKUs := len(skus)
 		if numSKUs == 0 {
 			return nil
 		}
@@ -70,7 +252,7 @@ func (m *Monitor) Start(ctx context.Context, interval time.Duration, workers int
 
 		var count int
 
-		for _, s := range m.activeSKUs {
+		for _, s := range skus {
 			count++
 
 			go func(ctx context.Context, s sku, delay time.Duration) {
@@ -78,120 +260,613 @@ func (m *Monitor) Start(ctx context.Context, interval time.Duration, workers int
 				case <-ctx.Done():
 					return
 				case <-time.After(delay):
-					m.pool.Enqueue(func(ctx context.Context) error {
-						err := m.checkStock(ctx, s)
-						if errors.Is(err, ErrNotAvailable) {
-							m.log.Debug("Product not available.", "product", s.prod, "country", s.country)
-							return nil
-						}
-
-						if err != nil {
-							m.log.Error("Failed to get buy now links.", "product", s.prod, "country", s.country, "error", err)
-						}
-
-						return nil
-					})
+					m.pool.Enqueue(m.checkJob(s))
 				}
 			}(ctx, s, delay*time.Duration(count))
 		}
 		return nil
 	})
 
+	if m.history != nil && m.fastInterval > 0 {
+		m.scheduler.Schedule(ctx, m.fastInterval, func(ctx context.Context) error {
+			m.activeSKUmu.Lock()
+			skus := maps.Clone(m.activeSKUs)
+			m.activeSKUmu.Unlock()
+
+			now := time.Now()
+
+			for code, s := range skus {
+				if !m.inDropWindow(code, now) {
+					continue
+				}
+
+				m.log.Debug("Fast polling SKU in predicted drop window.", "product", s.prod, "country", s.country)
+
+				go m.pool.Enqueue(m.checkJob(s))
+			}
+
+			return nil
+		})
+	}
+
+	if m.lifetime > 0 {
+		m.expireLegacy()
+
+		m.scheduler.Schedule(ctx, min(time.Hour, m.lifetime/4), func(context.Context) error {
+			m.sweep(time.Now())
+			return nil
+		})
+	}
+
 	go func() {
 		m.pool.Run(ctx, workers)
 	}()
 }
 
-func (m *Monitor) updateActiveSKUs() {
-	m.activeSKUmu.Lock()
-	defer m.activeSKUmu.Unlock()
+// expireLegacy sets the expiry of requests stored before the lifetime was
+// enabled. The expiries are spread over half a lifetime, so the users aren't
+// all asked to renew at once.
+func (m *Monitor) expireLegacy() {
+	var legacy []string
+
+	// Collect first, the store can't be modified while iterating.
+	for userID := range maps.Collect(m.store.All()) {
+		if _, ok := m.store.Expiry(userID); !ok {
+			legacy = append(legacy, userID)
+		}
+	}
+
+	var (
+		expires = time.Now().Add(m.lifetime)
+		spread  = m.lifetime / 2
+	)
+
+	for i, userID := range legacy {
+		at := expires.Add(spread / time.Duration(len(legacy)) * time.Duration(i))
+
+		if err := m.store.SetExpiry(userID, at); err != nil {
+			m.log.Error("Failed to set expiry.", "userID", userID, "error", err)
+		}
+	}
+}
+
+// sweep removes the expired requests and asks the users of requests that
+// expire soon to renew them.
+func (m *Monitor) sweep(now time.Time) {
+	for userID := range m.store.Expiring(now) {
+		m.log.Info("Subscription expired.", "userID", userID)
+		m.Remove(userID)
+	}
 
-	m.activeSKUs = make(map[string]sku)
+	if m.onExpiring == nil {
+		return
+	}
+
+	// Remind a few days ahead, but not right after subscribing.
+	reminder := min(72*time.Hour, m.lifetime/2)
+
+	for userID, expires := range m.store.Expiring(now.Add(reminder)) {
+		m.updateMu.Lock()
+
+		req, ok := m.store.Get(userID)
+		if !ok || req.ExpiryPrompted || len(req.Products) == 0 {
+			m.updateMu.Unlock()
+			continue
+		}
+
+		// Store directly, since an Update would renew the request.
+		req.ExpiryPrompted = true
+		err := m.store.Add(userID, req)
+
+		m.updateMu.Unlock()
+
+		if err != nil {
+			m.log.Error("Failed to update user in store.", "userID", userID, "error", err)
+			continue
+		}
+
+		m.onExpiring(userID, req, expires)
+	}
+}
+
+func (m *Monitor) checkJob(s sku) func(context.Context) error {
+	return func(ctx context.Context) error {
+		err := m.checkStock(ctx, s)
+		if errors.Is(err, ErrNotAvailable) {
+			m.log.Debug("Product not available.", "product", s.prod, "country", s.country)
+			return nil
+		}
+
+		if err != nil {
+			m.log.Error("Failed to get buy now links.", "product", s.prod, "country", s.country, "error", err)
+		}
+
+		return nil
+	}
+}
+
+// inDropWindow reports whether now falls into one of the most likely
+// predicted drop windows of the SKU.
+func (m *Monitor) inDropWindow(skuCode string, now time.Time) bool {
+	const topWindows = 3
+
+	for _, w := range m.history.Forecast(skuCode, now, topWindows) {
+		if !now.Before(w.Start) && now.Before(w.End) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// watchRequests keeps the active SKUs up to date with the stored requests.
+func (m *Monitor) watchRequests(ctx context.Context) {
+	// Watch before loading, so no change is missed. Changes already loaded
+	// are applied again, which doesn't alter the result.
+	events := m.store.Watch(ctx)
 
 	for userID, req := range m.store.All() {
-		for _, p := range req.Products {
-			sku := sku{
-				prod: nvidia.Product(p),
-			}
+		skus := m.skus(req)
 
-			for _, c := range req.Countries {
-				sku.country = nvidia.Country(c)
+		// Requests stored before the catalog changed may monitor products
+		// no longer offered in their countries.
+		if unoffered := m.Unoffered(req.Products, req.Countries); len(unoffered) > 0 {
+			m.log.Warn("Stored request monitors products not offered in its countries.",
+				"userID", userID, "products", unoffered, "countries", req.Countries, "monitored", len(skus))
+		}
 
-				skuCode := sku.prod.SKU(sku.country)
-				if skuCode == "" {
-					m.log.Warn("No SKU code found.", "product", sku.prod, "country", sku.country)
-					continue
-				}
+		m.updateActiveSKUs(userID, skus)
+	}
 
-				uID, _ := strconv.ParseInt(userID, 10, 64)
-				sku.users = append(m.activeSKUs[skuCode].users, uID)
+	go func() {
+		for e := range events {
+			m.updateActiveSKUs(e.Key, m.skus(e.Item))
+		}
+	}()
+}
 
-				m.activeSKUs[skuCode] = sku
-			}
+// skus returns the SKUs of the request offered by the stock source. Other
+// combinations of the requested products and countries, e.g. a retailer
+// product in a country without a page of it, are never checked.
+func (m *Monitor) skus(req Request) map[string]sku {
+	skus := req.skus()
+
+	maps.DeleteFunc(skus, func(_ string, s sku) bool {
+		return !m.Offers(s.prod.String(), s.country.String())
+	})
+
+	return skus
+}
+
+// updateActiveSKUs replaces the SKUs monitored for the user.
+func (m *Monitor) updateActiveSKUs(userID string, skus map[string]sku) {
+	m.activeSKUmu.Lock()
+	defer m.activeSKUmu.Unlock()
+
+	for code := range m.userSKUs[userID] {
+		if m.skuUsers[code]--; m.skuUsers[code] == 0 {
+			delete(m.skuUsers, code)
+			delete(m.activeSKUs, code)
 		}
 	}
+
+	delete(m.userSKUs, userID)
+
+	if len(skus) == 0 {
+		return
+	}
+
+	m.userSKUs[userID] = skus
+
+	for code, s := range skus {
+		m.skuUsers[code]++
+		m.activeSKUs[code] = s
+	}
+}
+
+// subscribers returns the users monitoring the SKU.
+func (m *Monitor) subscribers(skuCode string) []subscriber {
+	var users []subscriber
+
+	for userID, req := range m.store.Find(IndexSKU, skuCode) {
+		users = append(users, subscriber{
+			key:    userID,
+			chatID: req.ChatID,
+			req:    req,
+		})
+	}
+
+	return users
 }
 
 func (m *Monitor) checkStock(ctx context.Context, sku sku) error {
-	stocks, err := m.api.BuyNow(ctx, sku.prod, sku.country)
+	stocks, err := m.source.Check(ctx, sku.prod.String(), sku.country.String())
 	if err != nil {
 		return err
 	}
 
-	const (
-		nvidiaID      = "111"
-		nvidiaStoreID = "9595"
-	)
+	var offers []Offer
+
+	for _, o := range stocks {
+		if m.filter.Match(o) {
+			offers = append(offers, o)
+		}
+	}
+
+	// Record after filtering, placeholder listings such as the NVIDIA store
+	// itself aren't drops.
+	m.recordTransitions(sku, offers)
+
+	skuCode := sku.code()
+
+	m.restockedMu.Lock()
+	restocked := len(offers) > 0 && !m.restocked[skuCode]
+	m.restocked[skuCode] = len(offers) > 0
+	m.restockedMu.Unlock()
 
-	links := make(map[string]string)
+	if len(offers) == 0 {
+		return ErrNotAvailable
+	}
 
-	for _, s := range stocks {
-		// Needs to be other than Nvidia partner and store.
-		if s.PartnerID != nvidiaID && s.StoreID != nvidiaStoreID {
-			purchaiseLink := s.DirectPurchaseLink
+	now := time.Now()
 
-			if purchaiseLink == "" {
-				purchaiseLink = s.PurchaseLink
+	if restocked && m.onRestock != nil {
+		m.onRestock(Notification{
+			SKU:     skuCode,
+			Product: sku.prod.String(),
+			Country: sku.country.String(),
+			Offers:  offers,
+			Time:    now,
+		})
+	}
+
+	for _, user := range m.subscribers(skuCode) {
+		// Persistent subscribers are only notified once per restock.
+		if user.req.Persistent && !restocked {
+			continue
+		}
+
+		var userOffers []Offer
+
+		maxPrices := user.req.MaxPrices[sku.prod.String()]
+
+		for _, o := range offers {
+			if !matchRetailer(user.req.Retailers, user.req.ExcludedRetailers, o.Retailer) {
+				continue
 			}
 
-			links[s.RetailerName] = s.PurchaseLink
+			if !withinMaxPrice(maxPrices, o) {
+				continue
+			}
+
+			userOffers = append(userOffers, o)
+		}
+
+		if len(userOffers) == 0 {
+			continue
+		}
+
+		if err := m.outbox.Put(Notification{
+			ChatID:     user.chatID,
+			Language:   user.req.Language,
+			SKU:        skuCode,
+			Product:    sku.prod.String(),
+			Country:    sku.country.String(),
+			Offers:     userOffers,
+			Channels:   user.req.Channels,
+			Persistent: user.req.Persistent,
+			Time:       now,
+		}); err != nil {
+			m.log.Error("Failed to queue notification.", "chatID", user.chatID, "error", err)
+			continue
+		}
+
+		if !user.req.Persistent {
+			m.Unmonitor(user.key)
 		}
 	}
 
-	if len(links) == 0 {
-		return ErrNotAvailable
+	return nil
+}
+
+// withinMaxPrice reports whether the offer is at or below the limit of its
+// currency. Offers in currencies without a limit pass, offers without a known
+// price or currency can't be verified against the limits and don't.
+func withinMaxPrice(maxPrices map[string]float64, o Offer) bool {
+	if len(maxPrices) == 0 {
+		return true
+	}
+
+	if o.Currency == "" {
+		return false
+	}
+
+	maxPrice, ok := maxPrices[strings.ToUpper(o.Currency)]
+
+	return !ok || o.Price != 0 && float64(o.Price) <= maxPrice
+}
+
+// Indexes implements storage.Indexer.
+func (r Request) Indexes() map[string][]string {
+	return map[string][]string{IndexSKU: slices.Collect(maps.Keys(r.skus()))}
+}
+
+// skus returns the SKUs of the requested products and countries by code.
+func (r Request) skus() map[string]sku {
+	skus := make(map[string]sku)
+
+	for _, p := range r.Products {
+		for _, c := range r.Countries {
+			s := sku{
+				prod:    nvidia.Product(p),
+				country: nvidia.Country(c),
+			}
+
+			skus[s.code()] = s
+		}
 	}
 
-	for _, userID := range sku.users {
-		m.notCh <- Notification{
-			UserID:  userID,
-			Message: "Product " + sku.prod.String() + " is now available! Unsubscribed, use /monitor to subscribe again.",
-			URLs:    links,
+	return skus
+}
+
+// Currencies returns the currencies of the NVIDIA stores of the requested
+// countries, sorted and without duplicates.
+func (r Request) Currencies() []string {
+	var currencies []string
+
+	for _, c := range r.Countries {
+		if l, ok := nvidia.LookupLocale(nvidia.Country(c)); ok {
+			currencies = append(currencies, l.Currency)
+		}
+	}
+
+	slices.Sort(currencies)
+
+	return slices.Compact(currencies)
+}
+
+// SKUCode returns the NVIDIA SKU code of the product in the country, or
+// "product/country" for products of other sources such as retailers.
+func SKUCode(prod nvidia.Product, country nvidia.Country) string {
+	if code := prod.SKU(country); code != "" {
+		return code
+	}
+
+	return prod.String() + "/" + country.String()
+}
+
+func (s sku) code() string {
+	return SKUCode(s.prod, s.country)
+}
+
+func (fn StockSourceFunc) Check(ctx context.Context, product, country string) ([]Offer, error) {
+	return fn(ctx, product, country)
+}
+
+func (fn OutboxFunc) Put(n Notification) error {
+	return fn(n)
+}
+
+// Title returns the product title reported by the retailers, falling back to
+// the product name.
+func (n Notification) Title() string {
+	for _, o := range n.Offers {
+		if o.Title != "" {
+			return o.Title
+		}
+	}
+
+	return n.Product
+}
+
+// Offers reports whether the stock source offers the product in the country.
+// Sources that don't implement Catalog offer every product.
+func (m *Monitor) Offers(product, country string) bool {
+	c, ok := m.source.(Catalog)
+	return !ok || c.Offers(product, country)
+}
+
+// Unsupported returns the combinations of the requested products and countries
+// that aren't offered, e.g. "RTX 5090 FE (Norway)". They are not checked.
+func (m *Monitor) Unsupported(products, countries []string) []string {
+	var unsupported []string
+
+	for _, p := range products {
+		for _, c := range countries {
+			if !m.Offers(p, c) {
+				unsupported = append(unsupported, p+" ("+c+")")
+			}
+		}
+	}
+
+	return unsupported
+}
+
+// Unoffered returns the requested products that aren't offered in any of the
+// requested countries.
+func (m *Monitor) Unoffered(products, countries []string) []string {
+	var unoffered []string
+
+	for _, p := range products {
+		if !slices.ContainsFunc(countries, func(c string) bool {
+			return m.Offers(p, c)
+		}) {
+			unoffered = append(unoffered, p)
 		}
+	}
 
-		m.Unmonitor(strconv.FormatInt(userID, 10))
+	return unoffered
+}
+
+// Subscription returns the stored request of the user.
+func (m *Monitor) Subscription(userID string) (Request, bool) {
+	return m.store.Get(userID)
+}
+
+// Update applies fn to the stored request of the user, creating an empty one
+// if the user has none yet. Updates renew the request, see WithLifetime.
+func (m *Monitor) Update(userID string, fn func(*Request)) error {
+	m.updateMu.Lock()
+	defer m.updateMu.Unlock()
+
+	req, _ := m.store.Get(userID)
+	fn(&req)
+	req.ExpiryPrompted = false
+
+	if err := m.store.Add(userID, req); err != nil {
+		return err
+	}
+
+	if m.lifetime > 0 {
+		return m.store.SetExpiry(userID, time.Now().Add(m.lifetime))
+	}
+
+	return nil
+}
+
+// Renew extends the lifetime of the request of a user monitoring products and
+// returns its new expiry. It returns false if there is nothing to renew.
+func (m *Monitor) Renew(userID string) (time.Time, bool, error) {
+	req, ok := m.store.Get(userID)
+	if !ok || len(req.Products) == 0 || m.lifetime == 0 {
+		return time.Time{}, false, nil
+	}
+
+	if err := m.Update(userID, func(*Request) {}); err != nil {
+		return time.Time{}, false, err
+	}
+
+	expires, _ := m.store.Expiry(userID)
+
+	return expires, true, nil
+}
+
+// Reindex stores all requests again, which updates the SKU index and the
+// monitored SKUs after SKU codes changed.
+func (m *Monitor) Reindex() error {
+	m.updateMu.Lock()
+	defer m.updateMu.Unlock()
+
+	// Collect first, the store can't be modified while iterating.
+	for userID, req := range maps.Collect(m.store.All()) {
+		if err := m.store.Add(userID, req); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 func (m *Monitor) Monitor(userID string, products []string, countries []string) {
-	if err := m.store.Add(userID, Request{
-		Products:  products,
-		Countries: countries,
+	if err := m.Update(userID, func(req *Request) {
+		req.Products = products
+		req.Countries = countries
 	}); err != nil {
 		m.log.Error("Failed to add user to store.", "error", err)
-		return
 	}
+}
 
-	m.updateActiveSKUs()
+// Remove deletes the request of the user including the user preferences.
+func (m *Monitor) Remove(userID string) {
+	if err := m.store.Remove(userID); err != nil {
+		m.log.Error("Failed to remove user from store.", "error", err)
+	}
 }
 
+// Unmonitor stops monitoring for the user but keeps the user preferences.
 func (m *Monitor) Unmonitor(userID string) {
-	if err := m.store.Remove(userID); err != nil {
+	if _, ok := m.store.Get(userID); !ok {
+		return
+	}
+
+	if err := m.Update(userID, func(req *Request) {
+		req.Products = nil
+		req.Countries = nil
+	}); err != nil {
 		m.log.Error("Failed to remove user from store.", "error", err)
+	}
+}
+
+// recordTransitions stores every retailer whose stock count differs from the
+// previous check. Retailers missing from the response are treated as sold out.
+func (m *Monitor) recordTransitions(sku sku, stocks []Offer) {
+	if m.history == nil {
 		return
 	}
 
-	m.updateActiveSKUs()
+	skuCode := sku.code()
+	curr := make(map[string]int)
+	prices := make(map[string]Offer)
+
+	for _, o := range stocks {
+		curr[o.Retailer] += o.Stock
+
+		if o.Price != 0 {
+			prices[o.Retailer] = o
+		}
+	}
+
+	m.stocksMu.Lock()
+	prev := m.stocks[skuCode]
+	m.stocks[skuCode] = curr
+	m.stocksMu.Unlock()
+
+	now := time.Now()
+
+	record := func(retailer string, stock int) {
+		r := history.Record{
+			SKU:      skuCode,
+			Product:  sku.prod.String(),
+			Country:  sku.country.String(),
+			Retailer: retailer,
+			Stock:    stock,
+			Time:     now,
+		}
+
+		if offer, ok := prices[retailer]; ok {
+			r.Price = offer.Price.String()
+			r.Currency = offer.Currency
+		}
+
+		if err := m.history.Add(r); err != nil {
+			m.log.Error("Failed to record stock history.", "sku", skuCode, "retailer", retailer, "error", err)
+		}
+	}
+
+	for retailer, stock := range curr {
+		if prevStock, ok := prev[retailer]; ok && prevStock == stock || !ok && stock == 0 {
+			continue
+		}
+
+		record(retailer, stock)
+	}
+
+	for retailer, stock := range prev {
+		if _, ok := curr[retailer]; !ok && stock != 0 {
+			record(retailer, 0)
+		}
+	}
+}
+
+// Match reports whether the offer passes the filter.
+func (f RetailerFilter) Match(o Offer) bool {
+	if slices.Contains(f.ExcludedPartners, o.PartnerID) || slices.Contains(f.ExcludedStores, o.StoreID) {
+		return false
+	}
+
+	return matchRetailer(f.Retailers, f.ExcludedRetailers, o.Retailer)
+}
+
+// matchRetailer reports whether the retailer is allowed, i.e. it is in allowed
+// (or allowed is empty) and not in excluded. Names are compared ignoring case.
+func matchRetailer(allowed, excluded []string, retailer string) bool {
+	equal := func(name string) bool {
+		return strings.EqualFold(strings.TrimSpace(name), retailer)
+	}
+
+	if slices.ContainsFunc(excluded, equal) {
+		return false
+	}
+
+	return len(allowed) == 0 || slices.ContainsFunc(allowed, equal)
 }
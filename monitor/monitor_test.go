@@ -0,0 +1,359 @@
+package monitor_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/dyptan-io/rtx-sniper-bot/async"
+	"github.com/dyptan-io/rtx-sniper-bot/history"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor/monitortest"
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+const (
+	product = "RTX 5090 FE"
+	country = "Sweden"
+)
+
+var (
+	offer = monitor.Offer{
+		Title:    "NVIDIA GeForce RTX 5090",
+		Retailer: "Retailer N",
+		Stock:    1,
+		Price:    24990,
+		Currency: "SEK",
+		Link:     "https://retailerN.example/rtx-5090",
+	}
+	// nvidiaOffer only lists the product, see monitor.DefaultRetailerFilter.
+	nvidiaOffer = monitor.Offer{
+		Retailer:  "NVIDIA",
+		PartnerID: "111",
+		StoreID:   "9595",
+		Stock:     1,
+		Price:     24990,
+		Currency:  "SEK",
+	}
+)
+
+func newMonitor(t *testing.T, opts ...monitor.Option) (*monitor.Monitor, *monitortest.Source, *monitortest.Outbox) {
+	t.Helper()
+
+	var (
+		log    = slog.New(slog.NewTextHandler(io.Discard, nil))
+		source = monitortest.NewSource()
+		outbox = new(monitortest.Outbox)
+	)
+
+	return monitor.New(log, storage.New[monitor.Request](), nil, async.NewPool(), source, outbox, opts...), source, outbox
+}
+
+func subscribe(t *testing.T, m *monitor.Monitor, userID string, fn func(*monitor.Request)) {
+	t.Helper()
+
+	if err := m.Update(userID, func(req *monitor.Request) {
+		req.Products = []string{product}
+		req.Countries = []string{country}
+		req.ChatID = 123
+		fn(req)
+	}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+}
+
+func check(t *testing.T, m *monitor.Monitor, wantErr error) {
+	t.Helper()
+
+	if err := m.CheckStock(context.Background(), product, country); !errors.Is(err, wantErr) {
+		t.Fatalf("CheckStock() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestMonitorRestock(t *testing.T) {
+	var restocks []monitor.Notification
+
+	m, source, outbox := newMonitor(t, monitor.WithRestockHandler(func(n monitor.Notification) {
+		restocks = append(restocks, n)
+	}))
+
+	subscribe(t, m, "123", func(*monitor.Request) {})
+
+	source.Set(product, country, nvidiaOffer)
+	check(t, m, monitor.ErrNotAvailable)
+
+	if n := outbox.Notifications(); len(n) != 0 {
+		t.Fatalf("notifications = %v, want none while out of stock", n)
+	}
+
+	source.Set(product, country, nvidiaOffer, offer)
+	check(t, m, nil)
+
+	notifications := outbox.Notifications()
+	if len(notifications) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(notifications))
+	}
+
+	n := notifications[0]
+
+	if n.ChatID != 123 || n.Product != product || n.Country != country || n.Persistent {
+		t.Errorf("notification = %+v", n)
+	}
+
+	if want := []monitor.Offer{offer}; !reflect.DeepEqual(n.Offers, want) {
+		t.Errorf("offers = %+v, want %+v", n.Offers, want)
+	}
+
+	if len(restocks) != 1 || !reflect.DeepEqual(restocks[0].Offers, []monitor.Offer{offer}) {
+		t.Errorf("restocks = %+v, want one with the offer", restocks)
+	}
+
+	// The notified request is unmonitored but keeps the preferences.
+	req, ok := m.Subscription("123")
+	if !ok || len(req.Products) != 0 || len(req.Countries) != 0 || req.ChatID != 123 {
+		t.Errorf("Subscription() = %+v, %t, want unmonitored request", req, ok)
+	}
+
+	check(t, m, nil)
+
+	if n := outbox.Notifications(); len(n) != 1 {
+		t.Errorf("got %d notifications after unmonitoring, want 1", len(n))
+	}
+
+	if len(restocks) != 1 {
+		t.Errorf("got %d restocks while still in stock, want 1", len(restocks))
+	}
+}
+
+func TestMonitorPersistent(t *testing.T) {
+	m, source, outbox := newMonitor(t)
+
+	subscribe(t, m, "123", func(req *monitor.Request) {
+		req.Persistent = true
+	})
+
+	source.Set(product, country, offer)
+	check(t, m, nil)
+	check(t, m, nil)
+
+	if n := outbox.Notifications(); len(n) != 1 || !n[0].Persistent {
+		t.Fatalf("notifications = %+v, want one persistent notification per restock", n)
+	}
+
+	source.Set(product, country)
+	check(t, m, monitor.ErrNotAvailable)
+
+	source.Set(product, country, offer)
+	check(t, m, nil)
+
+	if n := outbox.Notifications(); len(n) != 2 {
+		t.Fatalf("got %d notifications, want 2 after the second restock", len(n))
+	}
+
+	if req, _ := m.Subscription("123"); len(req.Products) == 0 {
+		t.Error("persistent request was unmonitored")
+	}
+}
+
+func TestMonitorMaxPrice(t *testing.T) {
+	var (
+		cheap   = monitor.Offer{Retailer: "Cheap", Stock: 1, Price: 19990, Currency: "SEK"}
+		limit   = monitor.Offer{Retailer: "Limit", Stock: 1, Price: 20000, Currency: "sek"}
+		pricey  = monitor.Offer{Retailer: "Pricey", Stock: 1, Price: 24990, Currency: "SEK"}
+		euro    = monitor.Offer{Retailer: "Euro", Stock: 1, Price: 2500, Currency: "EUR"}
+		unknown = monitor.Offer{Retailer: "Unknown", Stock: 1, Price: 19990}
+		noPrice = monitor.Offer{Retailer: "No price", Stock: 1, Currency: "SEK"}
+	)
+
+	tests := []struct {
+		name      string
+		maxPrices map[string]map[string]float64
+		want      []monitor.Offer
+	}{
+		{
+			name: "no limit",
+			want: []monitor.Offer{cheap, limit, pricey, euro, unknown, noPrice},
+		},
+		{
+			name:      "limit of other product",
+			maxPrices: map[string]map[string]float64{"RTX 5080 FE": {"SEK": 1}},
+			want:      []monitor.Offer{cheap, limit, pricey, euro, unknown, noPrice},
+		},
+		{
+			// Offers in other currencies pass, offers without a currency
+			// or price can't be verified.
+			name:      "limit in SEK",
+			maxPrices: map[string]map[string]float64{product: {"SEK": 20000}},
+			want:      []monitor.Offer{cheap, limit, euro},
+		},
+		{
+			name:      "limits in SEK and EUR",
+			maxPrices: map[string]map[string]float64{product: {"SEK": 20000, "EUR": 2000}},
+			want:      []monitor.Offer{cheap, limit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, source, outbox := newMonitor(t)
+
+			subscribe(t, m, "123", func(req *monitor.Request) {
+				req.MaxPrices = tt.maxPrices
+			})
+
+			source.Set(product, country, cheap, limit, pricey, euro, unknown, noPrice)
+			check(t, m, nil)
+
+			notifications := outbox.Notifications()
+			if len(notifications) != 1 {
+				t.Fatalf("got %d notifications, want 1", len(notifications))
+			}
+
+			if got := notifications[0].Offers; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("offers = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("all above limit", func(t *testing.T) {
+		m, source, outbox := newMonitor(t)
+
+		subscribe(t, m, "123", func(req *monitor.Request) {
+			req.MaxPrices = map[string]map[string]float64{product: {"SEK": 20000}}
+		})
+
+		source.Set(product, country, pricey)
+		check(t, m, nil)
+
+		if n := outbox.Notifications(); len(n) != 0 {
+			t.Fatalf("notifications = %+v, want none", n)
+		}
+
+		if req, _ := m.Subscription("123"); len(req.Products) == 0 {
+			t.Error("request was unmonitored without a notification")
+		}
+	})
+}
+
+func TestMonitorUnmonitor(t *testing.T) {
+	m, source, outbox := newMonitor(t)
+
+	subscribe(t, m, "123", func(*monitor.Request) {})
+	subscribe(t, m, "456", func(req *monitor.Request) {
+		req.Retailers = []string{"Other retailer"}
+	})
+
+	source.Set(product, country, offer)
+
+	// Failed notifications keep the request to notify on the next check.
+	outbox.SetError(errors.New("disk full"))
+	check(t, m, nil)
+
+	if req, _ := m.Subscription("123"); len(req.Products) == 0 {
+		t.Fatal("request was unmonitored although the notification failed")
+	}
+
+	outbox.SetError(nil)
+	check(t, m, nil)
+
+	if n := outbox.Notifications(); len(n) != 1 || n[0].ChatID != 123 {
+		t.Fatalf("notifications = %+v, want one to chat 123", n)
+	}
+
+	if req, _ := m.Subscription("123"); len(req.Products) != 0 {
+		t.Errorf("notified request still monitors %v", req.Products)
+	}
+
+	// The retailer of the offer isn't requested, so nothing was sent.
+	if req, _ := m.Subscription("456"); len(req.Products) == 0 {
+		t.Error("request of another retailer was unmonitored")
+	}
+
+	if calls := source.Calls(product, country); calls != 2 {
+		t.Errorf("Calls() = %d, want 2", calls)
+	}
+}
+
+func TestMonitorSKUs(t *testing.T) {
+	m, source, _ := newMonitor(t)
+
+	// Retailer pages usually cover a few countries only.
+	source.Set(product, country)
+	source.Set("ASUS ROG Astral RTX 5090", "Germany")
+
+	if err := m.Update("123", func(req *monitor.Request) {
+		req.Products = []string{product, "ASUS ROG Astral RTX 5090"}
+		req.Countries = []string{country, "Germany"}
+	}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got := m.WatchedSKUs(context.Background())
+	want := []string{
+		monitor.SKUCode("ASUS ROG Astral RTX 5090", "Germany"),
+		monitor.SKUCode(product, country),
+	}
+
+	slices.Sort(want)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WatchedSKUs() = %v, want %v", got, want)
+	}
+}
+
+func TestMonitorUnoffered(t *testing.T) {
+	m, source, _ := newMonitor(t)
+
+	source.Set(product, country)
+	source.Set(product, "Germany")
+	source.Set("ASUS ROG Astral RTX 5090", "Germany")
+
+	products := []string{product, "ASUS ROG Astral RTX 5090", "RTX 4090 FE"}
+	countries := []string{country, "Germany"}
+
+	if got, want := m.Unoffered(products, countries), []string{"RTX 4090 FE"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unoffered() = %v, want %v", got, want)
+	}
+
+	want := []string{"ASUS ROG Astral RTX 5090 (Sweden)", "RTX 4090 FE (Sweden)", "RTX 4090 FE (Germany)"}
+
+	if got := m.Unsupported(products, countries); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unsupported() = %v, want %v", got, want)
+	}
+}
+
+func TestMonitorHistory(t *testing.T) {
+	hist := history.New(storage.New[history.Record]())
+	m, source, _ := newMonitor(t, monitor.WithHistory(hist))
+
+	skuCode := monitor.SKUCode(product, country)
+
+	// The NVIDIA listing is filtered and never recorded.
+	source.Set(product, country, nvidiaOffer)
+	check(t, m, monitor.ErrNotAvailable)
+
+	if records := hist.SKU(skuCode); len(records) != 0 {
+		t.Fatalf("records = %+v, want none for filtered listings", records)
+	}
+
+	source.Set(product, country, nvidiaOffer, offer)
+	check(t, m, nil)
+
+	source.Set(product, country, nvidiaOffer)
+	check(t, m, monitor.ErrNotAvailable)
+
+	records := hist.SKU(skuCode)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	for i, want := range []int{1, 0} {
+		if r := records[i]; r.Retailer != offer.Retailer || r.Stock != want {
+			t.Errorf("records[%d] = %+v, want %s with stock %d", i, r, offer.Retailer, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package monitortest provides fakes of the dependencies of the monitor for
+// tests.
+package monitortest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+)
+
+var (
+	_ monitor.StockSource = (*Source)(nil)
+	_ monitor.Catalog     = (*Source)(nil)
+	_ monitor.Outbox      = (*Outbox)(nil)
+)
+
+type (
+	// Source is a monitor.StockSource returning the offers set by the test.
+	// Its monitor.Catalog offers the products set.
+	Source struct {
+		items   map[item]result
+		calls   []item
+		itemsMu sync.Mutex
+	}
+
+	// Outbox is a monitor.Outbox recording the notifications.
+	Outbox struct {
+		notifications []monitor.Notification
+		err           error
+		mu            sync.Mutex
+	}
+
+	item struct {
+		product string
+		country string
+	}
+
+	result struct {
+		offers []monitor.Offer
+		err    error
+	}
+)
+
+func NewSource() *Source {
+	return &Source{
+		items: make(map[item]result),
+	}
+}
+
+// Set sets the offers of the product in the country.
+func (s *Source) Set(product, country string, offers ...monitor.Offer) {
+	s.itemsMu.Lock()
+	defer s.itemsMu.Unlock()
+
+	s.items[item{product, country}] = result{offers: offers}
+}
+
+// SetError makes checks of the product in the country fail with err.
+func (s *Source) SetError(product, country string, err error) {
+	s.itemsMu.Lock()
+	defer s.itemsMu.Unlock()
+
+	s.items[item{product, country}] = result{err: err}
+}
+
+// Check returns the offers set for the product in the country, none if they
+// weren't set.
+func (s *Source) Check(_ context.Context, product, country string) ([]monitor.Offer, error) {
+	s.itemsMu.Lock()
+	defer s.itemsMu.Unlock()
+
+	s.calls = append(s.calls, item{product, country})
+	r := s.items[item{product, country}]
+
+	return slices.Clone(r.offers), r.err
+}
+
+// Offers reports whether offers or an error were set for the product in the
+// country.
+func (s *Source) Offers(product, country string) bool {
+	s.itemsMu.Lock()
+	defer s.itemsMu.Unlock()
+
+	_, ok := s.items[item{product, country}]
+
+	return ok
+}
+
+// Calls returns the number of checks of the product in the country.
+func (s *Source) Calls(product, country string) int {
+	s.itemsMu.Lock()
+	defer s.itemsMu.Unlock()
+
+	var n int
+
+	for _, c := range s.calls {
+		if c == (item{product, country}) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Put records the notification, or fails with the error set by SetError.
+func (o *Outbox) Put(n monitor.Notification) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.err != nil {
+		return o.err
+	}
+
+	o.notifications = append(o.notifications, n)
+
+	return nil
+}
+
+// SetError makes Put fail with err, nil to succeed again.
+func (o *Outbox) SetError(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.err = err
+}
+
+// Notifications returns the recorded notifications.
+func (o *Outbox) Notifications() []monitor.Notification {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return slices.Clone(o.notifications)
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+)
+
+// NVIDIASource is a StockSource and Catalog checking the Founders Edition
+// cards with the NVIDIA API.
+type NVIDIASource struct {
+	client *nvidia.Client
+}
+
+func NewNVIDIASource(client *nvidia.Client) *NVIDIASource {
+	return &NVIDIASource{
+		client: client,
+	}
+}
+
+// Check returns the offers of the NVIDIA API.
+func (s *NVIDIASource) Check(ctx context.Context, product, country string) ([]Offer, error) {
+	stocks, err := s.client.Check(ctx, nvidia.Product(product), nvidia.Country(country))
+	if err != nil {
+		return nil, err
+	}
+
+	return NVIDIAOffers(stocks), nil
+}
+
+// Offers reports whether the catalog has a SKU code of the product in the
+// country.
+func (s *NVIDIASource) Offers(product, country string) bool {
+	return s.client.Offers(nvidia.Product(product), nvidia.Country(country))
+}
+
+// NVIDIAOffers converts offers of the NVIDIA API.
+func NVIDIAOffers(stocks []nvidia.StockResponse) []Offer {
+	offers := make([]Offer, 0, len(stocks))
+
+	for _, s := range stocks {
+		link := s.DirectPurchaseLink
+
+		if link == "" {
+			link = s.PurchaseLink
+		}
+
+		offers = append(offers, Offer{
+			Title:     s.ProductTitle,
+			Retailer:  s.RetailerName,
+			PartnerID: s.PartnerID,
+			StoreID:   s.StoreID,
+			Stock:     s.Stock,
+			Price:     s.Price,
+			Currency:  s.Currency,
+			Link:      link,
+			PageLink:  s.PurchaseLink,
+		})
+	}
+
+	return offers
+}
@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/dyptan-io/rtx-sniper-bot/i18n"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+)
+
+// Discord limits a message to 5 rows of 5 buttons and an embed to 25 fields.
+const (
+	discordButtonsPerRow = 5
+	discordMaxButtons    = 25
+	discordMaxFields     = 25
+	discordColor         = 0x76b900
+)
+
+// discordWebhookPrefixes are the prefixes of Discord webhook URLs.
+var discordWebhookPrefixes = []string{
+	"https://discord.com/api/webhooks/",
+	"https://discordapp.com/api/webhooks/",
+}
+
+type (
+	// Discord posts notifications as embeds to Discord webhook URLs.
+	Discord struct {
+		poster
+		templates *Templates
+		prefixes  []string
+	}
+
+	discordMessage struct {
+		Embeds     []discordEmbed     `json:"embeds"`
+		Components []discordComponent `json:"components,omitempty"`
+	}
+
+	discordEmbed struct {
+		Title     string         `json:"title"`
+		URL       string         `json:"url,omitempty"`
+		Color     int            `json:"color"`
+		Fields    []discordField `json:"fields"`
+		Timestamp string         `json:"timestamp,omitempty"`
+	}
+
+	discordField struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Inline bool   `json:"inline"`
+	}
+
+	discordComponent struct {
+		Type       int                `json:"type"`
+		Style      int                `json:"style,omitempty"`
+		Label      string             `json:"label,omitempty"`
+		URL        string             `json:"url,omitempty"`
+		Components []discordComponent `json:"components,omitempty"`
+	}
+)
+
+// NewDiscord creates a Discord notifier, client defaults to http.DefaultClient.
+func NewDiscord(templates *Templates, client *http.Client) *Discord {
+	d := Discord{
+		poster:    newPoster(),
+		templates: templates,
+		prefixes:  discordWebhookPrefixes,
+	}
+
+	if client != nil {
+		d.client = client
+	}
+
+	return &d
+}
+
+// Validate implements Validator, it fails for URLs other than Discord
+// webhooks.
+func (d *Discord) Validate(webhookURL string) error {
+	return validateURL(webhookURL, d.prefixes)
+}
+
+// Notify posts the notification to the Discord webhook URL.
+func (d *Discord) Notify(ctx context.Context, webhookURL string, n monitor.Notification) error {
+	if err := d.Validate(webhookURL); err != nil {
+		return err
+	}
+
+	title, err := d.templates.Render(ChannelDiscord, n)
+	if err != nil {
+		return err
+	}
+
+	embed := discordEmbed{
+		Title: title,
+		Color: discordColor,
+	}
+
+	if !n.Time.IsZero() {
+		embed.Timestamp = n.Time.UTC().Format("2006-01-02T15:04:05Z")
+	}
+
+	var (
+		lang    = i18n.Parse(n.Language)
+		buttons []discordComponent
+	)
+
+	for _, o := range n.Offers {
+		if embed.URL == "" {
+			embed.URL = o.Link
+		}
+
+		if len(embed.Fields) < discordMaxFields {
+			embed.Fields = append(embed.Fields, discordField{
+				Name:   o.Retailer,
+				Value:  offerSummary(lang, o) + "\n[" + o.Retailer + "](" + o.Link + ")",
+				Inline: true,
+			})
+		}
+
+		if len(buttons) < discordMaxButtons {
+			// Style 5 is a link button.
+			buttons = append(buttons, discordComponent{Type: 2, Style: 5, Label: o.Retailer, URL: o.Link})
+		}
+	}
+
+	msg := discordMessage{
+		Embeds: []discordEmbed{embed},
+	}
+
+	for i := 0; i < len(buttons); i += discordButtonsPerRow {
+		// Type 1 is an action row.
+		msg.Components = append(msg.Components, discordComponent{
+			Type:       1,
+			Components: buttons[i:min(i+discordButtonsPerRow, len(buttons))],
+		})
+	}
+
+	if len(msg.Components) > 0 {
+		// Webhooks not owned by an application drop the buttons unless
+		// with_components is set.
+		u, err := url.Parse(webhookURL)
+		if err != nil {
+			return err
+		}
+
+		query := u.Query()
+		query.Set("with_components", "true")
+		u.RawQuery = query.Encode()
+
+		webhookURL = u.String()
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return d.post(ctx, webhookURL, body, nil)
+}
+
+// offerSummary describes the stock and price of the offer.
+func offerSummary(lang i18n.Lang, o monitor.Offer) string {
+	summary := i18n.T(lang, "notify_in_stock")
+	if o.Stock > 0 {
+		summary = i18n.T(lang, "notify_stock", o.Stock)
+	}
+
+	if price := formatPrice(o.Price, o.Currency); price != "" {
+		summary += ", " + price
+	}
+
+	return summary
+}
@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDiscordNotify(t *testing.T) {
+	rec, srv := newReceiver(t)
+
+	d := NewDiscord(NewTemplates(), srv.Client())
+	d.prefixes = []string{srv.URL + "/api/webhooks/"}
+
+	if err := d.Notify(context.Background(), srv.URL+"/api/webhooks/1/token", testNotification(7)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if rec.count() != 1 {
+		t.Fatalf("got %d requests, want 1", rec.count())
+	}
+
+	req := rec.requests[0]
+
+	if req.URL.Path != "/api/webhooks/1/token" {
+		t.Errorf("path = %q, want /api/webhooks/1/token", req.URL.Path)
+	}
+
+	if got := req.URL.Query().Get("with_components"); got != "true" {
+		t.Errorf("with_components = %q, want true", got)
+	}
+
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var msg discordMessage
+
+	if err := json.Unmarshal(rec.bodies[0], &msg); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+
+	if len(msg.Embeds) != 1 {
+		t.Fatalf("got %d embeds, want 1", len(msg.Embeds))
+	}
+
+	embed := msg.Embeds[0]
+
+	for _, c := range []struct{ name, got, want string }{
+		{"title", embed.Title, "RTX 5090 FE is now available in Sweden!"},
+		{"url", embed.URL, "https://retailer1.example/rtx-5090"},
+		{"timestamp", embed.Timestamp, "2025-01-30T14:00:00Z"},
+		{"field name", embed.Fields[1].Name, "Retailer 2"},
+		{"field value", embed.Fields[1].Value, "1 in stock, 24990.00 SEK\n[Retailer 2](https://retailer2.example/rtx-5090)"},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+
+	if len(embed.Fields) != 7 {
+		t.Errorf("got %d fields, want 7", len(embed.Fields))
+	}
+
+	// 7 buttons need 2 action rows of at most 5 link buttons.
+	if len(msg.Components) != 2 || len(msg.Components[0].Components) != 5 || len(msg.Components[1].Components) != 2 {
+		t.Fatalf("unexpected action rows %+v", msg.Components)
+	}
+
+	button := msg.Components[1].Components[1]
+
+	if button.Type != 2 || button.Style != 5 || button.Label != "Retailer 7" || button.URL != "https://retailer7.example/rtx-5090" {
+		t.Errorf("unexpected button %+v", button)
+	}
+}
+
+func TestDiscordNotifyWithoutOffers(t *testing.T) {
+	rec, srv := newReceiver(t)
+
+	d := NewDiscord(NewTemplates(), srv.Client())
+	d.prefixes = []string{srv.URL + "/api/webhooks/"}
+
+	if err := d.Notify(context.Background(), srv.URL+"/api/webhooks/1/token", testNotification(0)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	// Messages without buttons work with all webhooks.
+	if rec.requests[0].URL.RawQuery != "" {
+		t.Errorf("query = %q, want none", rec.requests[0].URL.RawQuery)
+	}
+}
+
+func TestDiscordValidate(t *testing.T) {
+	d := NewDiscord(NewTemplates(), nil)
+
+	for _, tt := range []struct {
+		url   string
+		valid bool
+	}{
+		{"https://discord.com/api/webhooks/1/token", true},
+		{"https://discordapp.com/api/webhooks/1/token", true},
+		{"http://discord.com/api/webhooks/1/token", false},
+		{"https://discord.com.example/api/webhooks/1/token", false},
+		{"https://hooks.slack.com/services/T/B/X", false},
+		{"https://example.com/?https://discord.com/api/webhooks/", false},
+		{"", false},
+	} {
+		if err := d.Validate(tt.url); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) error = %v, want valid %v", tt.url, err, tt.valid)
+		}
+	}
+}
+
+func TestDiscordNotifyRejectsOtherURLs(t *testing.T) {
+	rec, srv := newReceiver(t)
+
+	// The stand-in isn't a Discord URL.
+	d := NewDiscord(NewTemplates(), srv.Client())
+
+	if err := d.Notify(context.Background(), srv.URL+"/api/webhooks/1/token", testNotification(1)); err == nil {
+		t.Fatal("Notify() succeeded for a URL other than Discord")
+	}
+
+	if rec.count() != 0 {
+		t.Errorf("got %d requests, want none", rec.count())
+	}
+}
+
+func TestDiscordNotifyErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		status     int
+		retryAfter string
+		wantRetry  bool
+		wantAfter  time.Duration
+	}{
+		{name: "rate limited", status: http.StatusTooManyRequests, retryAfter: "3", wantRetry: true, wantAfter: 3 * time.Second},
+		{name: "server error", status: http.StatusBadGateway, wantRetry: true},
+		{name: "client error", status: http.StatusNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, srv := newReceiver(t, tt.status)
+			rec.header = http.Header{"Retry-After": {tt.retryAfter}}
+
+			d := NewDiscord(NewTemplates(), srv.Client())
+			d.prefixes = []string{srv.URL + "/api/webhooks/"}
+
+			err := d.Notify(context.Background(), srv.URL+"/api/webhooks/1/token", testNotification(1))
+
+			var (
+				retryErr  *RetryError
+				statusErr *statusError
+			)
+
+			if !errors.As(err, &statusErr) || statusErr.code != tt.status {
+				t.Errorf("Notify() error = %v, want status %d", err, tt.status)
+			}
+
+			switch {
+			case tt.wantRetry && (!errors.As(err, &retryErr) || retryErr.After != tt.wantAfter):
+				t.Errorf("Notify() error = %#v, want RetryError after %s", err, tt.wantAfter)
+			case !tt.wantRetry && !errors.Is(err, ErrPermanent):
+				t.Errorf("Notify() error = %v, want ErrPermanent", err)
+			}
+
+			// The Dispatcher retries, not the notifier.
+			if rec.count() != 1 {
+				t.Errorf("got %d requests, want 1", rec.count())
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/async"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+)
+
+// Telegram allows about 30 messages per second overall, one message per
+// second to a chat and 20 messages per minute to a group.
+const (
+	DefaultGlobalInterval = time.Second / 30
+	DefaultChatInterval   = time.Second
+	DefaultGroupInterval  = time.Minute / 20
+)
+
+// ErrChatGone is returned by a SendFunc when the chat can't be delivered to
+// anymore, e.g. the user blocked the bot or it was removed from the group.
+var ErrChatGone = errors.New("chat is gone")
+
+// ErrPermanent is wrapped by errors of a SendFunc that won't succeed on retry,
+// e.g. a webhook that doesn't exist.
+var ErrPermanent = errors.New("permanent failure")
+
+type (
+	// SendFunc delivers a notification to its chat.
+	SendFunc func(ctx context.Context, n monitor.Notification) error
+
+	// RetryError is returned by a SendFunc when the delivery should be retried
+	// after the duration, or after the backoff of the Dispatcher if it's zero.
+	RetryError struct {
+		After time.Duration
+		Err   error
+	}
+
+	// Dispatcher queues notifications and delivers them respecting global and
+	// per chat rate limits.
+	Dispatcher struct {
+		send          SendFunc
+		queue         chan delivery
+		global        *async.Limiter
+		chats         map[int64]*async.Limiter
+		chatsMu       sync.Mutex
+		chatInterval  time.Duration
+		groupInterval time.Duration
+		attempts      int
+		backoff       time.Duration
+		onGone        func(chatID int64)
+		onDone        func(n monitor.Notification)
+		log           *slog.Logger
+	}
+
+	DispatcherOption func(*Dispatcher)
+
+	// delivery is a queued notification, or a message sent by its own func,
+	// with the number of failed attempts.
+	delivery struct {
+		n        monitor.Notification
+		send     func(ctx context.Context) error
+		attempts int
+	}
+)
+
+func NewDispatcher(log *slog.Logger, send SendFunc, opts ...DispatcherOption) *Dispatcher {
+	d := Dispatcher{
+		send:          send,
+		queue:         make(chan delivery, 1024),
+		global:        async.NewLimiter(DefaultGlobalInterval),
+		chats:         make(map[int64]*async.Limiter),
+		chatInterval:  DefaultChatInterval,
+		groupInterval: DefaultGroupInterval,
+		attempts:      5,
+		backoff:       time.Second,
+		log:           log,
+	}
+
+	for _, opt := range opts {
+		opt(&d)
+	}
+
+	return &d
+}
+
+// WithRateLimits sets the minimal intervals between messages overall, to a
+// private chat and to a group or channel.
+func WithRateLimits(global, chat, group time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.global = async.NewLimiter(global)
+		d.chatInterval = chat
+		d.groupInterval = group
+	}
+}
+
+// WithAttempts sets the number of delivery attempts of a notification.
+func WithAttempts(attempts int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.attempts = max(attempts, 1)
+	}
+}
+
+// WithBackoff sets the delay before the first retry of a failed delivery, it
+// doubles with every further attempt.
+func WithBackoff(backoff time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.backoff = backoff
+	}
+}
+
+// WithChatGone calls fn for chats that returned ErrChatGone.
+func WithChatGone(fn func(chatID int64)) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.onGone = fn
+	}
+}
+
+// WithDone calls fn after the notification was delivered or given up on.
+func WithDone(fn func(n monitor.Notification)) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.onDone = fn
+	}
+}
+
+// Enqueue adds the notification to the delivery queue.
+func (d *Dispatcher) Enqueue(n monitor.Notification) {
+	d.queue <- delivery{n: n}
+}
+
+// Send queues a message other than a notification to the chat, e.g. a prompt.
+// send is called with the rate limits and retries of notifications, but the
+// message isn't passed to WithDone.
+func (d *Dispatcher) Send(chatID int64, send func(ctx context.Context) error) {
+	d.queue <- delivery{n: monitor.Notification{ChatID: chatID}, send: send}
+}
+
+// Run delivers queued notifications with the number of workers until the
+// context is done.
+func (d *Dispatcher) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+
+	for range max(workers, 1) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case dl := <-d.queue:
+					d.deliver(ctx, dl)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// deliver makes an attempt to deliver dl. Deliveries to chats that aren't
+// ready yet and failed attempts are requeued for later, so a slow chat
+// doesn't hold up the worker for the others.
+func (d *Dispatcher) deliver(ctx context.Context, dl delivery) {
+	n, send := dl.n, dl.send
+
+	if send == nil {
+		send = func(ctx context.Context) error {
+			return d.send(ctx, n)
+		}
+	}
+
+	chat := d.chatLimiter(n.ChatID)
+
+	if next, ok := chat.Allow(time.Now()); !ok {
+		d.requeue(ctx, dl, next)
+		return
+	}
+
+	if err := d.global.Wait(ctx); err != nil {
+		return
+	}
+
+	err := send(ctx)
+	dl.attempts++
+
+	var retryErr *RetryError
+
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrChatGone):
+		d.log.Info("Chat is gone, removing subscription.", "chatID", n.ChatID, "error", err)
+
+		if d.onGone != nil {
+			d.onGone(n.ChatID)
+		}
+	case errors.Is(err, ErrPermanent), dl.attempts >= d.attempts:
+		d.log.Error("Failed to deliver notification.", "chatID", n.ChatID, "sku", n.SKU, "error", err)
+	case errors.As(err, &retryErr) && retryErr.After > 0:
+		d.log.Warn("Rate limited, retrying.", "chatID", n.ChatID, "after", retryErr.After)
+
+		// Too many requests applies to the whole bot.
+		d.global.Delay(retryErr.After)
+		chat.Delay(retryErr.After)
+		d.requeue(ctx, dl, time.Now().Add(retryErr.After))
+
+		return
+	default:
+		backoff := d.backoff << (dl.attempts - 1)
+
+		chat.Delay(backoff)
+		d.requeue(ctx, dl, time.Now().Add(backoff))
+
+		return
+	}
+
+	// Notifications interrupted by shutdown stay pending.
+	if d.onDone != nil && dl.send == nil && ctx.Err() == nil {
+		d.onDone(n)
+	}
+}
+
+// requeue queues dl again at the time, or drops it if the context is done
+// before.
+func (d *Dispatcher) requeue(ctx context.Context, dl delivery, at time.Time) {
+	time.AfterFunc(time.Until(at), func() {
+		select {
+		case <-ctx.Done():
+		case d.queue <- dl:
+		}
+	})
+}
+
+func (d *Dispatcher) chatLimiter(chatID int64) *async.Limiter {
+	d.chatsMu.Lock()
+	defer d.chatsMu.Unlock()
+
+	const maxIdleChats = 1024
+
+	// Forget idle chats to keep the map bounded.
+	if len(d.chats) > maxIdleChats {
+		now := time.Now()
+
+		for id, l := range d.chats {
+			if l.Idle(now) {
+				delete(d.chats, id)
+			}
+		}
+	}
+
+	l, ok := d.chats[chatID]
+	if !ok {
+		interval := d.chatInterval
+
+		// Group and channel chat IDs are negative.
+		if chatID < 0 {
+			interval = d.groupInterval
+		}
+
+		l = async.NewLimiter(interval)
+		d.chats[chatID] = l
+	}
+
+	return l
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("retry after %s: %v", e.After, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
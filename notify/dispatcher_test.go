@@ -0,0 +1,260 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+)
+
+func TestDispatcherSend(t *testing.T) {
+	var (
+		done      = make(chan monitor.Notification, 10)
+		delivered sync.WaitGroup
+		attempts  atomic.Int32
+		notified  atomic.Int32
+	)
+
+	d := NewDispatcher(slog.New(slog.NewTextHandler(io.Discard, nil)),
+		func(context.Context, monitor.Notification) error {
+			notified.Add(1)
+			delivered.Done()
+
+			return nil
+		},
+		WithRateLimits(0, 0, 0),
+		WithDone(func(n monitor.Notification) { done <- n }),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go d.Run(ctx, 2)
+
+	delivered.Add(2)
+
+	// The prompt is rate limited like notifications.
+	d.Send(1, func(context.Context) error {
+		if attempts.Add(1) == 1 {
+			return &RetryError{After: time.Millisecond, Err: errors.New("too many requests")}
+		}
+
+		delivered.Done()
+
+		return nil
+	})
+
+	d.Enqueue(monitor.Notification{ID: "1/telegram", ChatID: 1})
+
+	delivered.Wait()
+
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("sent the prompt %d times, want 2", got)
+	}
+
+	if got := notified.Load(); got != 1 {
+		t.Errorf("sent the notification %d times, want 1", got)
+	}
+
+	// Only the notification is done.
+	select {
+	case n := <-done:
+		if n.ID != "1/telegram" {
+			t.Errorf("done with %q, want the notification", n.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the notification wasn't done")
+	}
+
+	select {
+	case n := <-done:
+		t.Errorf("done with %+v, want only the notification", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// attempt is a delivery attempt seen by a SendFunc.
+type attempt struct {
+	chatID int64
+	at     time.Time
+}
+
+// runDispatcher runs a dispatcher with send until the test ends, the
+// attempts are sent to the returned channel.
+func runDispatcher(t *testing.T, workers int, send func(attempts int, n monitor.Notification) error, opts ...DispatcherOption) (*Dispatcher, <-chan attempt) {
+	t.Helper()
+
+	var (
+		attempts = make(chan attempt, 100)
+		counts   = make(map[string]int)
+		countsMu sync.Mutex
+	)
+
+	d := NewDispatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), func(_ context.Context, n monitor.Notification) error {
+		countsMu.Lock()
+		counts[n.ID]++
+		count := counts[n.ID]
+		countsMu.Unlock()
+
+		attempts <- attempt{chatID: n.ChatID, at: time.Now()}
+
+		return send(count, n)
+	}, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx, workers)
+
+	return d, attempts
+}
+
+func next(t *testing.T, attempts <-chan attempt) attempt {
+	t.Helper()
+
+	select {
+	case a := <-attempts:
+		return a
+	case <-time.After(5 * time.Second):
+		t.Fatal("no delivery attempt")
+		return attempt{}
+	}
+}
+
+func TestDispatcherGlobalLimit(t *testing.T) {
+	const interval = 50 * time.Millisecond
+
+	d, attempts := runDispatcher(t, 3, func(int, monitor.Notification) error { return nil },
+		WithRateLimits(interval, 0, 0))
+
+	for chatID := range int64(3) {
+		d.Enqueue(monitor.Notification{ID: "1/telegram", ChatID: chatID + 1})
+	}
+
+	first := next(t, attempts)
+	next(t, attempts)
+
+	if last := next(t, attempts); last.at.Sub(first.at) < 2*interval-10*time.Millisecond {
+		t.Errorf("3 deliveries took %s, want at least %s", last.at.Sub(first.at), 2*interval)
+	}
+}
+
+func TestDispatcherChatLimit(t *testing.T) {
+	const interval = 200 * time.Millisecond
+
+	// A single worker must not wait for the busy chat.
+	d, attempts := runDispatcher(t, 1, func(int, monitor.Notification) error { return nil },
+		WithRateLimits(0, interval, interval))
+
+	d.Enqueue(monitor.Notification{ID: "1/telegram", ChatID: 1})
+	d.Enqueue(monitor.Notification{ID: "2/telegram", ChatID: 1})
+	d.Enqueue(monitor.Notification{ID: "3/telegram", ChatID: 2})
+
+	var got []attempt
+	for range 3 {
+		got = append(got, next(t, attempts))
+	}
+
+	if got[0].chatID != 1 || got[1].chatID != 2 || got[2].chatID != 1 {
+		t.Fatalf("delivered to chats %d, %d, %d, want 1, 2, 1", got[0].chatID, got[1].chatID, got[2].chatID)
+	}
+
+	if got[1].at.Sub(got[0].at) >= interval {
+		t.Errorf("chat 2 waited %s for chat 1", got[1].at.Sub(got[0].at))
+	}
+
+	if got[2].at.Sub(got[0].at) < interval-10*time.Millisecond {
+		t.Errorf("chat 1 was sent to %s apart, want at least %s", got[2].at.Sub(got[0].at), interval)
+	}
+}
+
+func TestDispatcherRetryAfter(t *testing.T) {
+	const after = 100 * time.Millisecond
+
+	done := make(chan monitor.Notification, 1)
+
+	d, attempts := runDispatcher(t, 2, func(attempts int, _ monitor.Notification) error {
+		if attempts == 1 {
+			return &RetryError{After: after, Err: errors.New("too many requests")}
+		}
+
+		return nil
+	}, WithRateLimits(0, 0, 0), WithBackoff(time.Hour), WithDone(func(n monitor.Notification) { done <- n }))
+
+	d.Enqueue(monitor.Notification{ID: "1/telegram", ChatID: 1})
+
+	first := next(t, attempts)
+
+	// Other chats wait for the global delay as well.
+	d.Enqueue(monitor.Notification{ID: "2/telegram", ChatID: 2})
+
+	for range 2 {
+		if a := next(t, attempts); a.at.Sub(first.at) < after-10*time.Millisecond {
+			t.Errorf("chat %d was sent to %s after the rate limit, want at least %s", a.chatID, a.at.Sub(first.at), after)
+		}
+	}
+
+	for range 2 {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the notifications weren't done")
+		}
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		err      error
+		attempts int
+		gone     bool
+	}{
+		{name: "chat gone", err: fmt.Errorf("%w: bot was blocked", ErrChatGone), attempts: 1, gone: true},
+		{name: "permanent", err: fmt.Errorf("%w: 404 Not Found", ErrPermanent), attempts: 1},
+		{name: "attempts exhausted", err: errors.New("502 Bad Gateway"), attempts: 3},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				done = make(chan monitor.Notification, 1)
+				gone = make(chan int64, 1)
+			)
+
+			d, attempts := runDispatcher(t, 1, func(int, monitor.Notification) error { return tt.err },
+				WithRateLimits(0, 0, 0), WithAttempts(3), WithBackoff(time.Millisecond),
+				WithDone(func(n monitor.Notification) { done <- n }),
+				WithChatGone(func(chatID int64) { gone <- chatID }))
+
+			d.Enqueue(monitor.Notification{ID: "1/telegram", ChatID: 1})
+
+			// The delivery is dropped from the outbox.
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the notification wasn't done")
+			}
+
+			if got := len(attempts); got != tt.attempts {
+				t.Errorf("got %d attempts, want %d", got, tt.attempts)
+			}
+
+			select {
+			case chatID := <-gone:
+				if !tt.gone || chatID != 1 {
+					t.Errorf("chat %d is gone, want gone %t", chatID, tt.gone)
+				}
+			default:
+				if tt.gone {
+					t.Error("the chat wasn't removed")
+				}
+			}
+		})
+	}
+}
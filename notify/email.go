@@ -0,0 +1,207 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/i18n"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+)
+
+// DefaultSMTPTimeout bounds sends without an SMTPConfig.Timeout.
+const DefaultSMTPTimeout = 30 * time.Second
+
+type (
+	SMTPConfig struct {
+		// Addr is the host:port of the SMTP server.
+		Addr     string
+		Username string
+		Password string
+		From     string
+		// StartTLS requires the server to support STARTTLS.
+		StartTLS bool
+		// TLSConfig defaults to verifying the host of Addr.
+		TLSConfig *tls.Config
+		// Timeout bounds a single send, so a stuck server can't hang the
+		// caller. It defaults to DefaultSMTPTimeout.
+		Timeout time.Duration
+	}
+
+	// Email sends notifications as HTML and plain text emails over SMTP.
+	Email struct {
+		cfg       SMTPConfig
+		templates *Templates
+	}
+)
+
+func NewEmail(cfg SMTPConfig, templates *Templates) *Email {
+	return &Email{
+		cfg:       cfg,
+		templates: templates,
+	}
+}
+
+// Notify sends the notification to the email address.
+func (e *Email) Notify(ctx context.Context, to string, n monitor.Notification) error {
+	text, err := e.templates.Render(ChannelEmail, n)
+	if err != nil {
+		return err
+	}
+
+	html, err := e.templates.Render(ChannelEmail+htmlSuffix, n)
+	if err != nil {
+		return err
+	}
+
+	subject := i18n.T(i18n.Parse(n.Language), "notify_available", n.Title(), n.Country)
+
+	return e.Send(ctx, to, subject, text, html)
+}
+
+// Send sends an email with a plain text and an optional HTML body.
+func (e *Email) Send(ctx context.Context, to, subject, text, html string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return errors.New("invalid line break in email header")
+	}
+
+	msg, err := e.message(to, subject, text, html)
+	if err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(e.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("parsing SMTP address: %w", err)
+	}
+
+	timeout := e.cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSMTPTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", e.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	defer c.Close()
+
+	if e.cfg.StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+
+		tlsConfig := e.cfg.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: host}
+		}
+
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starting TLS: %w", err)
+		}
+	}
+
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, host)); err != nil {
+			return fmt.Errorf("authenticating: %w", err)
+		}
+	}
+
+	if err := c.Mail(e.cfg.From); err != nil {
+		return err
+	}
+
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// message builds a multipart/alternative MIME message.
+func (e *Email) message(to, subject, text, html string) ([]byte, error) {
+	var (
+		buf  bytes.Buffer
+		body = multipart.NewWriter(&buf)
+	)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", body.Boundary())
+
+	parts := []struct{ contentType, content string }{
+		{"text/plain", text},
+		{"text/html", html},
+	}
+
+	for _, p := range parts {
+		if p.content == "" {
+			continue
+		}
+
+		pw, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+
+		if _, err := qw.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
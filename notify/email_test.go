@@ -0,0 +1,300 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpServer is an in-process SMTP server accepting a single message.
+type smtpServer struct {
+	addr     string
+	startTLS *tls.Config
+
+	mu   sync.Mutex
+	tls  bool
+	auth string
+	from string
+	rcpt string
+	data string
+}
+
+func newSMTPServer(t *testing.T, startTLS *tls.Config) *smtpServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { ln.Close() })
+
+	srv := smtpServer{addr: ln.Addr().String(), startTLS: startTLS}
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		srv.serve(conn)
+	}()
+
+	return &srv
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	var (
+		r     = bufio.NewReader(conn)
+		reply = func(line string) { io.WriteString(conn, line+"\r\n") }
+	)
+
+	reply("220 localhost ESMTP")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		s.mu.Lock()
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if s.startTLS != nil && !s.tls {
+				reply("250-localhost")
+				reply("250-STARTTLS")
+			} else {
+				reply("250-localhost")
+			}
+
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 Ready to start TLS")
+
+			conn = tls.Server(conn, s.startTLS)
+			r = bufio.NewReader(conn)
+			s.tls = true
+		case "AUTH":
+			s.auth = arg
+			reply("235 Authentication successful")
+		case "MAIL":
+			s.from = arg
+			reply("250 OK")
+		case "RCPT":
+			s.rcpt = arg
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+
+				data.WriteString(line)
+			}
+
+			s.data = data.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			s.mu.Unlock()
+
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+
+		s.mu.Unlock()
+	}
+}
+
+func TestEmailNotify(t *testing.T) {
+	srv := newSMTPServer(t, nil)
+
+	e := NewEmail(SMTPConfig{
+		Addr:     srv.addr,
+		Username: "sniper",
+		Password: "secret",
+		From:     "sniper@example.com",
+	}, NewTemplates())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := e.Notify(ctx, "user@example.com", testNotification(2)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if want := "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00sniper\x00secret")); srv.auth != want {
+		t.Errorf("AUTH %q, want %q", srv.auth, want)
+	}
+
+	if srv.from != "FROM:<sniper@example.com>" || srv.rcpt != "TO:<user@example.com>" {
+		t.Errorf("MAIL %q, RCPT %q", srv.from, srv.rcpt)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(srv.data))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "RTX 5090 FE is now available in Sweden!" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, %v", msg.Header.Get("Content-Type"), err)
+	}
+
+	parts := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// NextPart decodes the quoted-printable body.
+		body, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parts[p.Header.Get("Content-Type")] = string(body)
+	}
+
+	if text := parts["text/plain; charset=utf-8"]; !strings.Contains(text, "Retailer 2: 1 in stock, 24990.00 SEK\r\nhttps://retailer2.example/rtx-5090") {
+		t.Errorf("text part = %q", text)
+	}
+
+	if html := parts["text/html; charset=utf-8"]; !strings.Contains(html, `<a href="https://retailer2.example/rtx-5090">Retailer 2</a>`) {
+		t.Errorf("HTML part = %q", html)
+	}
+}
+
+func TestEmailStartTLS(t *testing.T) {
+	// The httptest certificate is valid for 127.0.0.1.
+	https := httptest.NewTLSServer(http.NotFoundHandler())
+	defer https.Close()
+
+	srv := newSMTPServer(t, &tls.Config{Certificates: https.TLS.Certificates})
+
+	tlsConfig := https.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	tlsConfig.ServerName = "127.0.0.1"
+
+	e := NewEmail(SMTPConfig{
+		Addr:      srv.addr,
+		Username:  "sniper",
+		Password:  "secret",
+		From:      "sniper@example.com",
+		StartTLS:  true,
+		TLSConfig: tlsConfig,
+	}, NewTemplates())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := e.Send(ctx, "user@example.com", "Subject", "Text", ""); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if !srv.tls || srv.data == "" {
+		t.Errorf("message sent with TLS %v, data %q", srv.tls, srv.data)
+	}
+}
+
+func TestEmailRequiresStartTLS(t *testing.T) {
+	srv := newSMTPServer(t, nil)
+
+	e := NewEmail(SMTPConfig{Addr: srv.addr, From: "sniper@example.com", StartTLS: true}, NewTemplates())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := e.Send(ctx, "user@example.com", "Subject", "Text", ""); err == nil {
+		t.Fatal("Send() succeeded without STARTTLS")
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if srv.data != "" {
+		t.Errorf("message sent without TLS: %q", srv.data)
+	}
+}
+
+func TestEmailRejectsHeaderInjection(t *testing.T) {
+	e := NewEmail(SMTPConfig{Addr: "127.0.0.1:1", From: "sniper@example.com"}, NewTemplates())
+
+	if err := e.Send(context.Background(), "user@example.com\r\nBcc: other@example.com", "Subject", "Text", ""); err == nil {
+		t.Fatal("Send() accepted a line break in the recipient")
+	}
+}
+
+func TestEmailTimeout(t *testing.T) {
+	// The server accepts connections but never greets.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	e := NewEmail(SMTPConfig{Addr: ln.Addr().String(), From: "sniper@example.com", Timeout: 50 * time.Millisecond}, NewTemplates())
+
+	start := time.Now()
+
+	if err := e.Notify(context.Background(), "user@example.com", testNotification(1)); err == nil {
+		t.Fatal("Notify() succeeded without a server greeting")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Notify() returned after %s, want the timeout", elapsed)
+	}
+}
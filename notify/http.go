@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// poster posts JSON bodies once. Failures worth retrying are returned as
+	// RetryError, others wrap ErrPermanent, so only the caller retries.
+	poster struct {
+		client *http.Client
+	}
+
+	// statusError is a non-2xx response of the receiver.
+	statusError struct {
+		code int
+	}
+)
+
+func newPoster() poster {
+	return poster{
+		client: http.DefaultClient,
+	}
+}
+
+func (p poster) post(ctx context.Context, url string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header = header.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		// Network errors may be temporary, unlike a cancelled context.
+		if ctx.Err() != nil {
+			return err
+		}
+
+		return &RetryError{Err: err}
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+
+	statusErr := &statusError{code: resp.StatusCode}
+
+	// Client errors other than rate limiting won't succeed on retry.
+	if !statusErr.retryable() {
+		return fmt.Errorf("%w: %w", ErrPermanent, statusErr)
+	}
+
+	return &RetryError{After: retryAfter(resp.Header, time.Now()), Err: statusErr}
+}
+
+// retryAfter returns the delay of the Retry-After header in seconds or as a
+// date, zero if it's missing or invalid.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+
+	return 0
+}
+
+// validateURL fails if the URL doesn't start with any of the prefixes. The URL
+// isn't part of the error, since webhook URLs contain secret tokens.
+func validateURL(rawURL string, prefixes []string) error {
+	if !slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(rawURL, prefix) }) {
+		return errors.New("unsupported webhook URL")
+	}
+
+	return nil
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.code)
+}
+
+func (e *statusError) retryable() bool {
+	return e.code == http.StatusTooManyRequests || e.code >= 500
+}
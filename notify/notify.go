@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+)
+
+// Channel names used for templates and monitor.Request channels.
+const (
+	ChannelTelegram = "telegram"
+	ChannelDiscord  = "discord"
+	ChannelSlack    = "slack"
+	ChannelEmail    = "email"
+)
+
+type (
+	// Notifier delivers notifications to a target of its channel, such as a
+	// webhook URL.
+	Notifier interface {
+		Notify(ctx context.Context, target string, n monitor.Notification) error
+	}
+
+	// Validator is implemented by notifiers checking the targets set by
+	// users, e.g. that a webhook URL belongs to the service.
+	Validator interface {
+		Validate(target string) error
+	}
+)
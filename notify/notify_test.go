@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+)
+
+// receiver is a webhook stand-in answering with the next status, 200 once
+// all statuses are used.
+type receiver struct {
+	mu       sync.Mutex
+	header   http.Header
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	t.Helper()
+
+	rec := receiver{statuses: statuses}
+	srv := httptest.NewServer(&rec)
+	t.Cleanup(srv.Close)
+
+	return &rec, srv
+}
+
+func (rec *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.requests = append(rec.requests, r)
+	rec.bodies = append(rec.bodies, body)
+
+	for name, values := range rec.header {
+		w.Header()[name] = values
+	}
+
+	status := http.StatusOK
+
+	if len(rec.statuses) > 0 {
+		status, rec.statuses = rec.statuses[0], rec.statuses[1:]
+	}
+
+	w.WriteHeader(status)
+}
+
+func (rec *receiver) count() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return len(rec.requests)
+}
+
+// testNotification returns a notification with the number of offers.
+func testNotification(offers int) monitor.Notification {
+	n := monitor.Notification{
+		ChatID:  1,
+		SKU:     "1147625",
+		Product: "RTX 5090 FE",
+		Country: "Sweden",
+		Time:    time.Date(2025, 1, 30, 14, 0, 0, 0, time.UTC),
+	}
+
+	for i := range offers {
+		n.Offers = append(n.Offers, monitor.Offer{
+			Retailer: fmt.Sprintf("Retailer %d", i+1),
+			Stock:    i,
+			Price:    24990,
+			Currency: "SEK",
+			Link:     fmt.Sprintf("https://retailer%d.example/rtx-5090", i+1),
+		})
+	}
+
+	return n
+}
@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+// Outbox persists notifications until they are delivered, so producers don't
+// wait for the delivery and pending notifications survive a restart. Run hands
+// the stored deliveries on, separately from Put, so a full delivery queue
+// doesn't hold up the producers. Every
+// channel of a notification is a separate delivery, so a failing channel
+// neither holds back nor repeats the others.
+type Outbox struct {
+	store      storage.Store[monitor.Notification]
+	ready      chan struct{}
+	seq        atomic.Uint64
+	inflight   map[string]bool
+	inflightMu sync.Mutex
+	log        *slog.Logger
+}
+
+func NewOutbox(log *slog.Logger, store storage.Store[monitor.Notification]) *Outbox {
+	return &Outbox{
+		store:    store,
+		ready:    make(chan struct{}, 1),
+		inflight: make(map[string]bool),
+		log:      log,
+	}
+}
+
+// Put stores the deliveries of the notification with new IDs and wakes up
+// Run. Once it returns, the notification survives a restart. If a delivery
+// can't be stored, the others are removed again so a retry doesn't repeat
+// them.
+func (o *Outbox) Put(n monitor.Notification) error {
+	// IDs sort in the order of the notifications.
+	id := fmt.Sprintf("%020d-%06d", time.Now().UnixNano(), o.seq.Add(1)%1_000_000)
+
+	if err := o.add(deliveries(id, n)); err != nil {
+		return err
+	}
+
+	o.wake()
+
+	return nil
+}
+
+// Done removes the delivered notification from the outbox.
+func (o *Outbox) Done(n monitor.Notification) error {
+	if err := o.store.Remove(n.ID); err != nil {
+		return err
+	}
+
+	o.inflightMu.Lock()
+	delete(o.inflight, n.ID)
+	o.inflightMu.Unlock()
+
+	return nil
+}
+
+// Run passes the pending deliveries to fn in order until the context is done.
+// Every delivery is passed once until it's Done, or again after a restart.
+func (o *Outbox) Run(ctx context.Context, fn func(monitor.Notification)) {
+	o.split()
+
+	for {
+		for _, n := range o.pending() {
+			fn(n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.ready:
+		}
+	}
+}
+
+func (o *Outbox) wake() {
+	select {
+	case o.ready <- struct{}{}:
+	default:
+	}
+}
+
+// add stores the deliveries, or none of them.
+func (o *Outbox) add(ds []monitor.Notification) error {
+	for i, d := range ds {
+		if err := o.store.Add(d.ID, d); err != nil {
+			for _, stored := range ds[:i] {
+				if err := o.store.Remove(stored.ID); err != nil {
+					o.log.Error("Failed to remove notification from outbox.", "id", stored.ID, "error", err)
+				}
+			}
+
+			return fmt.Errorf("storing notification in outbox: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// split replaces the notifications stored before deliveries per channel.
+func (o *Outbox) split() {
+	var legacy []monitor.Notification
+
+	for _, n := range o.store.All() {
+		if n.Channel == "" {
+			legacy = append(legacy, n)
+		}
+	}
+
+	for _, n := range legacy {
+		if err := o.add(deliveries(n.ID, n)); err != nil {
+			o.log.Error("Failed to split notification in outbox.", "id", n.ID, "error", err)
+			continue
+		}
+
+		if err := o.store.Remove(n.ID); err != nil {
+			o.log.Error("Failed to remove notification from outbox.", "id", n.ID, "error", err)
+		}
+	}
+}
+
+func (o *Outbox) pending() []monitor.Notification {
+	o.inflightMu.Lock()
+	defer o.inflightMu.Unlock()
+
+	var pending []monitor.Notification
+
+	for id, n := range o.store.All() {
+		if !o.inflight[id] && n.Channel != "" {
+			o.inflight[id] = true
+			pending = append(pending, n)
+		}
+	}
+
+	slices.SortFunc(pending, func(a, b monitor.Notification) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return pending
+}
+
+// deliveries splits the notification into the delivery to the chat and one
+// per additional channel, e.g. "<id>/telegram" and "<id>/discord".
+func deliveries(id string, n monitor.Notification) []monitor.Notification {
+	n.ID, n.Channel = id+"/"+ChannelTelegram, ChannelTelegram
+	ds := []monitor.Notification{n}
+
+	for _, channel := range slices.Sorted(maps.Keys(n.Channels)) {
+		d := n
+		d.ID, d.Channel = id+"/"+channel, channel
+		ds = append(ds, d)
+	}
+
+	return ds
+}
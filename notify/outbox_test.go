@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/storage"
+)
+
+func TestOutboxDeliveriesPerChannel(t *testing.T) {
+	store := storage.New[monitor.Notification]()
+	outbox := NewOutbox(slog.New(slog.NewTextHandler(io.Discard, nil)), store)
+
+	n := testNotification(1)
+	n.Channels = map[string]string{ChannelDiscord: "https://discord.com/api/webhooks/1/token", ChannelEmail: "user@example.com"}
+
+	if err := outbox.Put(n); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// Put stores the deliveries before the producer moves on.
+	if got := len(maps.Collect(store.All())); got != 3 {
+		t.Fatalf("stored %d deliveries before Run, want 3", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	delivered := make(chan monitor.Notification, 10)
+
+	go outbox.Run(ctx, func(n monitor.Notification) { delivered <- n })
+
+	var channels []string
+
+	for range 3 {
+		select {
+		case d := <-delivered:
+			if _, ok := store.Get(d.ID); !ok {
+				t.Errorf("delivery %s passed on before it was stored", d.ID)
+			}
+
+			channels = append(channels, d.Channel)
+
+			if err := outbox.Done(d); err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("got deliveries %v, want 3", channels)
+		}
+	}
+
+	slices.Sort(channels)
+
+	if want := []string{ChannelDiscord, ChannelEmail, ChannelTelegram}; !slices.Equal(channels, want) {
+		t.Errorf("channels = %v, want %v", channels, want)
+	}
+
+	if got := len(maps.Collect(store.All())); got != 0 {
+		t.Errorf("%d deliveries left after Done, want 0", got)
+	}
+}
+
+func TestOutboxSplitsStoredNotifications(t *testing.T) {
+	store := storage.New[monitor.Notification]()
+
+	// Stored before deliveries per channel.
+	n := testNotification(1)
+	n.ID = "00000000000000000001-000001"
+	n.Channels = map[string]string{ChannelSlack: "https://hooks.slack.com/services/T/B/X"}
+
+	if err := store.Add(n.ID, n); err != nil {
+		t.Fatal(err)
+	}
+
+	outbox := NewOutbox(slog.New(slog.NewTextHandler(io.Discard, nil)), store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	delivered := make(chan monitor.Notification, 10)
+
+	go outbox.Run(ctx, func(n monitor.Notification) { delivered <- n })
+
+	var ids []string
+
+	for range 2 {
+		select {
+		case d := <-delivered:
+			ids = append(ids, d.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("got deliveries %v, want 2", ids)
+		}
+	}
+
+	if want := []string{n.ID + "/slack", n.ID + "/telegram"}; !slices.Equal(ids, want) {
+		t.Errorf("deliveries = %v, want %v", ids, want)
+	}
+
+	if _, ok := store.Get(n.ID); ok {
+		t.Error("the stored notification wasn't replaced")
+	}
+}
+
+// failingStore fails to add items once the limit is reached.
+type failingStore struct {
+	storage.Store[monitor.Notification]
+	limit int
+}
+
+func (s *failingStore) Add(key string, n monitor.Notification) error {
+	if s.limit == 0 {
+		return errors.New("disk full")
+	}
+
+	s.limit--
+
+	return s.Store.Add(key, n)
+}
+
+func TestOutboxPutFails(t *testing.T) {
+	store := &failingStore{Store: storage.New[monitor.Notification](), limit: 1}
+	outbox := NewOutbox(slog.New(slog.NewTextHandler(io.Discard, nil)), store)
+
+	n := testNotification(1)
+	n.Channels = map[string]string{ChannelEmail: "user@example.com"}
+
+	if err := outbox.Put(n); err == nil {
+		t.Fatal("Put() succeeded without storing the deliveries")
+	}
+
+	// The stored delivery is removed, so the retry doesn't repeat it.
+	if got := slices.Collect(maps.Keys(maps.Collect(store.All()))); len(got) != 0 {
+		t.Errorf("deliveries %v left after a failed Put, want none", got)
+	}
+}
@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/dyptan-io/rtx-sniper-bot/i18n"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+)
+
+// Slack limits a section to 10 fields and an actions block to 25 elements.
+const (
+	slackFieldsPerSection = 10
+	slackMaxButtons       = 25
+)
+
+// slackWebhookPrefixes are the prefixes of Slack incoming webhook URLs.
+var slackWebhookPrefixes = []string{"https://hooks.slack.com/"}
+
+type (
+	// Slack posts notifications as blocks to Slack incoming webhook URLs.
+	Slack struct {
+		poster
+		templates *Templates
+		prefixes  []string
+	}
+
+	slackMessage struct {
+		Text   string       `json:"text"`
+		Blocks []slackBlock `json:"blocks"`
+	}
+
+	slackBlock struct {
+		Type     string         `json:"type"`
+		Text     *slackText     `json:"text,omitempty"`
+		Fields   []slackText    `json:"fields,omitempty"`
+		Elements []slackElement `json:"elements,omitempty"`
+	}
+
+	slackText struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+
+	slackElement struct {
+		Type string    `json:"type"`
+		Text slackText `json:"text"`
+		URL  string    `json:"url"`
+	}
+)
+
+// NewSlack creates a Slack notifier, client defaults to http.DefaultClient.
+func NewSlack(templates *Templates, client *http.Client) *Slack {
+	s := Slack{
+		poster:    newPoster(),
+		templates: templates,
+		prefixes:  slackWebhookPrefixes,
+	}
+
+	if client != nil {
+		s.client = client
+	}
+
+	return &s
+}
+
+// Validate implements Validator, it fails for URLs other than Slack incoming
+// webhooks.
+func (s *Slack) Validate(webhookURL string) error {
+	return validateURL(webhookURL, s.prefixes)
+}
+
+// Notify posts the notification to the Slack incoming webhook URL.
+func (s *Slack) Notify(ctx context.Context, webhookURL string, n monitor.Notification) error {
+	if err := s.Validate(webhookURL); err != nil {
+		return err
+	}
+
+	title, err := s.templates.Render(ChannelSlack, n)
+	if err != nil {
+		return err
+	}
+
+	msg := slackMessage{
+		Text: title,
+		Blocks: []slackBlock{{
+			Type: "header",
+			Text: &slackText{Type: "plain_text", Text: title},
+		}},
+	}
+
+	var (
+		lang    = i18n.Parse(n.Language)
+		fields  []slackText
+		buttons []slackElement
+	)
+
+	for _, o := range n.Offers {
+		fields = append(fields, slackText{
+			Type: "mrkdwn",
+			Text: "*<" + o.Link + "|" + o.Retailer + ">*\n" + offerSummary(lang, o),
+		})
+
+		if len(buttons) < slackMaxButtons {
+			buttons = append(buttons, slackElement{
+				Type: "button",
+				Text: slackText{Type: "plain_text", Text: o.Retailer},
+				URL:  o.Link,
+			})
+		}
+	}
+
+	for i := 0; i < len(fields); i += slackFieldsPerSection {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type:   "section",
+			Fields: fields[i:min(i+slackFieldsPerSection, len(fields))],
+		})
+	}
+
+	if len(buttons) > 0 {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type:     "actions",
+			Elements: buttons,
+		})
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return s.post(ctx, webhookURL, body, nil)
+}
@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSlackNotify(t *testing.T) {
+	rec, srv := newReceiver(t)
+
+	s := NewSlack(NewTemplates(), srv.Client())
+	s.prefixes = []string{srv.URL + "/services/"}
+
+	if err := s.Notify(context.Background(), srv.URL+"/services/T/B/X", testNotification(12)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if rec.count() != 1 {
+		t.Fatalf("got %d requests, want 1", rec.count())
+	}
+
+	if got := rec.requests[0].Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var msg slackMessage
+
+	if err := json.Unmarshal(rec.bodies[0], &msg); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+
+	const title = "RTX 5090 FE is now available in Sweden!"
+
+	if msg.Text != title {
+		t.Errorf("text = %q, want %q", msg.Text, title)
+	}
+
+	// A header, 2 sections of at most 10 fields and the buttons.
+	var types []string
+
+	for _, b := range msg.Blocks {
+		types = append(types, b.Type)
+	}
+
+	if len(types) != 4 || types[0] != "header" || types[1] != "section" || types[2] != "section" || types[3] != "actions" {
+		t.Fatalf("blocks = %v, want [header section section actions]", types)
+	}
+
+	if got := msg.Blocks[0].Text; got == nil || got.Type != "plain_text" || got.Text != title {
+		t.Errorf("header = %+v", got)
+	}
+
+	if len(msg.Blocks[1].Fields) != 10 || len(msg.Blocks[2].Fields) != 2 {
+		t.Errorf("got %d and %d fields, want 10 and 2", len(msg.Blocks[1].Fields), len(msg.Blocks[2].Fields))
+	}
+
+	field := msg.Blocks[1].Fields[1]
+
+	if want := "*<https://retailer2.example/rtx-5090|Retailer 2>*\n1 in stock, 24990.00 SEK"; field.Type != "mrkdwn" || field.Text != want {
+		t.Errorf("field = %+v, want mrkdwn %q", field, want)
+	}
+
+	buttons := msg.Blocks[3].Elements
+
+	if len(buttons) != 12 {
+		t.Fatalf("got %d buttons, want 12", len(buttons))
+	}
+
+	if b := buttons[11]; b.Type != "button" || b.Text.Text != "Retailer 12" || b.URL != "https://retailer12.example/rtx-5090" {
+		t.Errorf("unexpected button %+v", b)
+	}
+}
+
+func TestSlackValidate(t *testing.T) {
+	s := NewSlack(NewTemplates(), nil)
+
+	for _, tt := range []struct {
+		url   string
+		valid bool
+	}{
+		{"https://hooks.slack.com/services/T/B/X", true},
+		{"http://hooks.slack.com/services/T/B/X", false},
+		{"https://hooks.slack.com.example/services/T/B/X", false},
+		{"https://discord.com/api/webhooks/1/token", false},
+		{"", false},
+	} {
+		if err := s.Validate(tt.url); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) error = %v, want valid %v", tt.url, err, tt.valid)
+		}
+	}
+}
+
+func TestSlackNotifyRejectsOtherURLs(t *testing.T) {
+	rec, srv := newReceiver(t)
+
+	s := NewSlack(NewTemplates(), srv.Client())
+
+	if err := s.Notify(context.Background(), srv.URL+"/services/T/B/X", testNotification(1)); err == nil {
+		t.Fatal("Notify() succeeded for a URL other than Slack")
+	}
+
+	if rec.count() != 0 {
+		t.Errorf("got %d requests, want none", rec.count())
+	}
+}
+
+func TestSlackNotifyRateLimited(t *testing.T) {
+	rec, srv := newReceiver(t, http.StatusTooManyRequests)
+	rec.header = http.Header{"Retry-After": {"5"}}
+
+	s := NewSlack(NewTemplates(), srv.Client())
+	s.prefixes = []string{srv.URL + "/services/"}
+
+	err := s.Notify(context.Background(), srv.URL+"/services/T/B/X", testNotification(1))
+
+	var retryErr *RetryError
+
+	if !errors.As(err, &retryErr) || retryErr.After != 5*time.Second {
+		t.Fatalf("Notify() error = %v, want RetryError after 5s", err)
+	}
+
+	if rec.count() != 1 {
+		t.Errorf("got %d requests, want 1", rec.count())
+	}
+}
@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/dyptan-io/rtx-sniper-bot/i18n"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+)
+
+const offersTemplate = `{{range .Offers}}
+{{.Retailer}}: {{if .Stock}}{{t $.Language "notify_stock" .Stock}}{{else}}{{t $.Language "notify_in_stock"}}{{end}}{{with price .Price .Currency}}, {{.}}{{end}}{{end}}`
+
+var defaultTemplates = map[string]string{
+	ChannelTelegram: `{{t .Language "notify_available" .Title .Country}}
+` + offersTemplate + `
+{{if not .Persistent}}
+{{t .Language "notify_unsubscribed"}}{{end}}`,
+	ChannelDiscord: `{{t .Language "notify_available" .Title .Country}}`,
+	ChannelSlack:   `{{t .Language "notify_available" .Title .Country}}`,
+	ChannelEmail: `{{t .Language "notify_available" .Title .Country}}
+{{range .Offers}}
+{{.Retailer}}: {{if .Stock}}{{t $.Language "notify_stock" .Stock}}{{else}}{{t $.Language "notify_in_stock"}}{{end}}{{with price .Price .Currency}}, {{.}}{{end}}
+{{.Link}}
+{{end}}{{if not .Persistent}}
+{{t .Language "notify_unsubscribed"}}{{end}}`,
+	ChannelEmail + htmlSuffix: `<html><body>
+<h2>{{t .Language "notify_available" .Title .Country}}</h2>
+<ul>{{range .Offers}}
+<li><a href="{{.Link}}">{{.Retailer}}</a>: {{if .Stock}}{{t $.Language "notify_stock" .Stock}}{{else}}{{t $.Language "notify_in_stock"}}{{end}}{{with price .Price .Currency}}, {{.}}{{end}}</li>{{end}}
+</ul>
+{{if not .Persistent}}<p>{{t .Language "notify_unsubscribed"}}</p>{{end}}
+</body></html>`,
+}
+
+// htmlSuffix marks templates rendered with html/template, such as "email.html".
+const htmlSuffix = ".html"
+
+type (
+	// Templates renders notifications with a template per channel.
+	Templates struct {
+		tmpls map[string]executor
+	}
+
+	executor interface {
+		Execute(w io.Writer, data any) error
+	}
+)
+
+// NewTemplates creates Templates with the default template of every channel.
+func NewTemplates() *Templates {
+	t := Templates{
+		tmpls: make(map[string]executor),
+	}
+
+	for name, text := range defaultTemplates {
+		tmpl, err := parseTemplate(name, text)
+		if err != nil {
+			panic(err)
+		}
+
+		t.tmpls[name] = tmpl
+	}
+
+	return &t
+}
+
+// LoadTemplates creates Templates overriding the defaults with the files
+// named <channel>.tmpl found in dir, or <channel>.html.tmpl for HTML.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := NewTemplates()
+
+	for name := range defaultTemplates {
+		data, err := os.ReadFile(filepath.Join(dir, name+".tmpl"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		tmpl, err := parseTemplate(name, string(data))
+		if err != nil {
+			return nil, err
+		}
+
+		t.tmpls[name] = tmpl
+	}
+
+	return t, nil
+}
+
+// Render executes the template of the channel with the notification. HTML
+// templates are rendered with the channel name suffixed by ".html".
+func (t *Templates) Render(channel string, n monitor.Notification) (string, error) {
+	tmpl, ok := t.tmpls[channel]
+	if !ok {
+		return "", fmt.Errorf("no template for channel %q", channel)
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, n); err != nil {
+		return "", fmt.Errorf("rendering %s template: %w", channel, err)
+	}
+
+	return buf.String(), nil
+}
+
+func parseTemplate(name, text string) (executor, error) {
+	funcs := map[string]any{
+		"price": formatPrice,
+		"t": func(lang, key string, args ...any) string {
+			return i18n.T(i18n.Parse(lang), key, args...)
+		},
+	}
+
+	var (
+		tmpl executor
+		err  error
+	)
+
+	if strings.HasSuffix(name, htmlSuffix) {
+		tmpl, err = htmltemplate.New(name).Funcs(funcs).Parse(text)
+	} else {
+		tmpl, err = template.New(name).Funcs(funcs).Parse(text)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+// formatPrice formats the price with its currency, or returns an empty
+// string if the price is unknown.
+func formatPrice(p nvidia.Price, currency string) string {
+	if p == 0 {
+		return ""
+	}
+
+	if currency == "" {
+		return p.String()
+	}
+
+	return p.String() + " " + currency
+}
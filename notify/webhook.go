@@ -0,0 +1,256 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body,
+// prefixed with "sha256=".
+const SignatureHeader = "X-Sniper-Signature-256"
+
+var (
+	// ErrSignature is returned by VerifyEvent for bodies not matching the
+	// signature.
+	ErrSignature = errors.New("invalid signature")
+	// ErrStaleEvent is returned by VerifyEvent for events outside of the
+	// accepted age, e.g. replayed ones.
+	ErrStaleEvent = errors.New("stale event")
+)
+
+type (
+	// Event is the JSON payload posted by the Webhook.
+	Event struct {
+		SKU       string          `json:"sku"`
+		Product   string          `json:"product"`
+		Country   string          `json:"country"`
+		Retailers []EventRetailer `json:"retailers"`
+		Timestamp time.Time       `json:"timestamp"`
+	}
+
+	EventRetailer struct {
+		Name     string  `json:"name"`
+		Stock    int     `json:"stock"`
+		Price    float64 `json:"price,omitempty"`
+		Currency string  `json:"currency,omitempty"`
+		Link     string  `json:"link"`
+	}
+
+	// Webhook posts notifications as signed JSON events to a set of URLs.
+	Webhook struct {
+		poster
+		attempts     int
+		backoff      time.Duration
+		urls         []string
+		secret       []byte
+		deadLetter   io.Writer
+		deadLetterMu sync.Mutex
+	}
+
+	WebhookOption func(*Webhook)
+
+	deadLetter struct {
+		Time  time.Time `json:"time"`
+		URL   string    `json:"url"`
+		Error string    `json:"error"`
+		Event Event     `json:"event"`
+	}
+)
+
+// NewEvent converts the notification to its webhook payload.
+func NewEvent(n monitor.Notification) Event {
+	e := Event{
+		SKU:       n.SKU,
+		Product:   n.Product,
+		Country:   n.Country,
+		Retailers: make([]EventRetailer, 0, len(n.Offers)),
+		Timestamp: n.Time.UTC(),
+	}
+
+	for _, o := range n.Offers {
+		e.Retailers = append(e.Retailers, EventRetailer{
+			Name:     o.Retailer,
+			Stock:    o.Stock,
+			Price:    float64(o.Price),
+			Currency: o.Currency,
+			Link:     o.Link,
+		})
+	}
+
+	return e
+}
+
+func NewWebhook(urls []string, secret string, opts ...WebhookOption) *Webhook {
+	w := Webhook{
+		poster:   newPoster(),
+		attempts: 5,
+		backoff:  time.Second,
+		urls:     urls,
+		secret:   []byte(secret),
+	}
+
+	for _, opt := range opts {
+		opt(&w)
+	}
+
+	return &w
+}
+
+// WithWebhookClient sets the HTTP client used for delivery.
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(w *Webhook) {
+		w.client = client
+	}
+}
+
+// WithRetry sets the number of delivery attempts and the initial backoff,
+// which doubles after every failed attempt.
+func WithRetry(attempts int, backoff time.Duration) WebhookOption {
+	return func(w *Webhook) {
+		w.attempts = max(attempts, 1)
+		w.backoff = backoff
+	}
+}
+
+// WithDeadLetter writes undeliverable events as JSON lines to dst.
+func WithDeadLetter(dst io.Writer) WebhookOption {
+	return func(w *Webhook) {
+		w.deadLetter = dst
+	}
+}
+
+// Notify posts the notification to every URL. Events that can't be delivered
+// after all attempts are written to the dead-letter log.
+func (w *Webhook) Notify(ctx context.Context, n monitor.Notification) error {
+	event := NewEvent(n)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, u := range w.urls {
+		header := http.Header{
+			SignatureHeader: []string{Sign(w.secret, body)},
+		}
+
+		if err := w.postRetrying(ctx, u, body, header); err != nil {
+			errs = append(errs, fmt.Errorf("delivering to %s: %w", u, err))
+
+			if err := w.writeDeadLetter(u, event, err); err != nil {
+				errs = append(errs, fmt.Errorf("writing dead letter: %w", err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// postRetrying posts the body to the URL until it succeeds, fails permanently
+// or runs out of attempts. Webhooks aren't delivered by a Dispatcher, so they
+// retry themselves.
+func (w *Webhook) postRetrying(ctx context.Context, url string, body []byte, header http.Header) error {
+	var (
+		err     error
+		backoff = w.backoff
+	)
+
+	for attempt := range w.attempts {
+		if attempt > 0 {
+			wait := backoff
+			backoff *= 2
+
+			var retryErr *RetryError
+
+			if errors.As(err, &retryErr) && retryErr.After > wait {
+				wait = retryErr.After
+			}
+
+			select {
+			case <-ctx.Done():
+				return errors.Join(err, ctx.Err())
+			case <-time.After(wait):
+			}
+		}
+
+		err = w.post(ctx, url, body, header)
+
+		var retryErr *RetryError
+
+		if !errors.As(err, &retryErr) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (w *Webhook) writeDeadLetter(url string, event Event, cause error) error {
+	if w.deadLetter == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(deadLetter{
+		Time:  time.Now().UTC(),
+		URL:   url,
+		Error: cause.Error(),
+		Event: event,
+	})
+	if err != nil {
+		return err
+	}
+
+	w.deadLetterMu.Lock()
+	defer w.deadLetterMu.Unlock()
+
+	_, err = w.deadLetter.Write(append(data, '\n'))
+
+	return err
+}
+
+// Sign returns the value of the SignatureHeader for the body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the body.
+func Verify(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// VerifyEvent verifies the signature of a received body and decodes its
+// event. The signed timestamp must be at most maxAge away from now, which
+// should allow for the retries of the sender.
+func VerifyEvent(secret, body []byte, signature string, maxAge time.Duration, now time.Time) (Event, error) {
+	if !Verify(secret, body, signature) {
+		return Event{}, ErrSignature
+	}
+
+	var e Event
+
+	if err := json.Unmarshal(body, &e); err != nil {
+		return Event{}, fmt.Errorf("decoding event: %w", err)
+	}
+
+	if age := now.Sub(e.Timestamp); age > maxAge || age < -maxAge {
+		return Event{}, fmt.Errorf("%w: timestamp %s", ErrStaleEvent, e.Timestamp.Format(time.RFC3339))
+	}
+
+	return e, nil
+}
@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifyRetries(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		statuses   []int
+		requests   int
+		deadLetter bool
+	}{
+		{name: "delivered", requests: 1},
+		{name: "rate limited", statuses: []int{429, 429}, requests: 3},
+		{name: "server error", statuses: []int{http.StatusBadGateway}, requests: 2},
+		{name: "client error", statuses: []int{http.StatusNotFound}, requests: 1, deadLetter: true},
+		{name: "persistently failing", statuses: []int{503, 503, 503}, requests: 3, deadLetter: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, srv := newReceiver(t, tt.statuses...)
+
+			var deadLetters bytes.Buffer
+
+			w := NewWebhook([]string{srv.URL}, "secret",
+				WithWebhookClient(srv.Client()), WithRetry(3, time.Millisecond), WithDeadLetter(&deadLetters))
+
+			err := w.Notify(context.Background(), testNotification(2))
+			if (err != nil) != tt.deadLetter {
+				t.Errorf("Notify() error = %v, want error %t", err, tt.deadLetter)
+			}
+
+			if rec.count() != tt.requests {
+				t.Errorf("got %d requests, want %d", rec.count(), tt.requests)
+			}
+
+			if !tt.deadLetter {
+				if deadLetters.Len() != 0 {
+					t.Errorf("dead letters = %s, want none", deadLetters.String())
+				}
+
+				return
+			}
+
+			var dl deadLetter
+
+			if err := json.Unmarshal(deadLetters.Bytes(), &dl); err != nil {
+				t.Fatalf("decoding dead letter: %v", err)
+			}
+
+			if dl.URL != srv.URL || dl.Event.SKU != "1147625" || len(dl.Event.Retailers) != 2 {
+				t.Errorf("dead letter = %+v", dl)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifySigns(t *testing.T) {
+	rec, srv := newReceiver(t)
+
+	w := NewWebhook([]string{srv.URL}, "secret", WithWebhookClient(srv.Client()))
+
+	if err := w.Notify(context.Background(), testNotification(1)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if got := rec.requests[0].Header.Get(SignatureHeader); !Verify([]byte("secret"), rec.bodies[0], got) {
+		t.Errorf("signature %q doesn't match the body", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 30, 14, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-1", 0},
+		{"Thu, 30 Jan 2025 14:00:30 GMT", 30 * time.Second},
+		{"Thu, 30 Jan 2025 13:00:00 GMT", 0},
+		{"soon", 0},
+	} {
+		if got := retryAfter(http.Header{"Retry-After": {tt.value}}, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookNotifyCancelled(t *testing.T) {
+	_, srv := newReceiver(t, http.StatusServiceUnavailable)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := NewWebhook([]string{srv.URL}, "secret", WithWebhookClient(srv.Client()), WithRetry(3, time.Hour))
+
+	if err := w.Notify(ctx, testNotification(1)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Notify() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// The HMAC-SHA256 example of the Wikipedia HMAC article.
+	const want = "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+
+	body := []byte("The quick brown fox jumps over the lazy dog")
+
+	if got := Sign([]byte("key"), body); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		secret    string
+		body      string
+		signature string
+		want      bool
+	}{
+		{"valid", "key", string(body), want, true},
+		{"tampered body", "key", "The quick brown fox jumps over the lazy cat", want, false},
+		{"other secret", "other", string(body), want, false},
+		{"missing prefix", "key", string(body), want[len("sha256="):], false},
+		{"empty signature", "key", string(body), "", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify([]byte(tt.secret), []byte(tt.body), tt.signature); got != tt.want {
+				t.Errorf("Verify() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyEvent(t *testing.T) {
+	secret := []byte("secret")
+	n := testNotification(1)
+
+	body, err := json.Marshal(NewEvent(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := bytes.Replace(body, []byte(`"stock":0`), []byte(`"stock":9`), 1)
+
+	for _, tt := range []struct {
+		name      string
+		body      []byte
+		signature string
+		now       time.Time
+		wantErr   error
+	}{
+		{name: "valid", body: body, signature: Sign(secret, body), now: n.Time.Add(time.Minute)},
+		{name: "tampered body", body: tampered, signature: Sign(secret, body), now: n.Time, wantErr: ErrSignature},
+		{name: "stale timestamp", body: body, signature: Sign(secret, body), now: n.Time.Add(time.Hour), wantErr: ErrStaleEvent},
+		{name: "future timestamp", body: body, signature: Sign(secret, body), now: n.Time.Add(-time.Hour), wantErr: ErrStaleEvent},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := VerifyEvent(secret, tt.body, tt.signature, 5*time.Minute, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyEvent() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && (e.SKU != n.SKU || !e.Timestamp.Equal(n.Time)) {
+				t.Errorf("VerifyEvent() = %+v", e)
+			}
+		})
+	}
+}
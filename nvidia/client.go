@@ -19,6 +19,8 @@ type (
 		PartnerID          string `json:"partnerId"`
 		StoreID            string `json:"storeId"`
 		Stock              int    `json:"stock"`
+		Price              Price  `json:"price"`
+		Currency           string `json:"currency"`
 	}
 
 	Client struct {
@@ -46,19 +48,74 @@ func NewClient(baseURL *url.URL, opts ...Option) *Client {
 }
 
 func (c *Client) BuyNow(ctx context.Context, prod Product, country Country) ([]StockResponse, error) {
+	sku := prod.SKU(country)
+	locale, ok := LookupLocale(country)
+
+	if sku == "" || !ok {
+		return nil, fmt.Errorf("no SKU code of %s in %s", prod, country)
+	}
+
+	stocks, err := c.BuyNowSKU(ctx, sku, locale.API)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stocks {
+		if stocks[i].Currency == "" {
+			stocks[i].Currency = locale.Currency
+		}
+	}
+
+	return stocks, nil
+}
+
+// Offers reports whether the product is sold in the country, i.e. there is
+// a store with a SKU code of the product.
+func (c *Client) Offers(prod Product, country Country) bool {
+	return prod.SKU(country) != "" && country.Locale() != ""
+}
+
+// Check implements monitor.StockSource.
+func (c *Client) Check(ctx context.Context, prod Product, country Country) ([]StockResponse, error) {
+	return c.BuyNow(ctx, prod, country)
+}
+
+// BuyNowSKU returns the offers of the raw SKU code in the locale.
+func (c *Client) BuyNowSKU(ctx context.Context, sku, locale string) ([]StockResponse, error) {
 	params := make(url.Values)
 
-	params.Set("sku", prod.SKU(country))
-	params.Set("locale", country.Locale())
+	params.Set("sku", sku)
+	params.Set("locale", locale)
+
+	body, err := c.get(ctx, "/products/v1/buy-now", params)
+	if err != nil {
+		return nil, fmt.Errorf("fetching stock data: %w", err)
+	}
+
+	var stockData []StockResponse
+
+	unescaped, err := strconv.Unquote(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(unescaped), &stockData); err != nil {
+		return nil, err
+	}
 
-	buyNowURL := url.URL{
+	return stockData, nil
+}
+
+// get returns the body of the API response.
+func (c *Client) get(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	apiURL := url.URL{
 		Scheme:   c.apiURL.Scheme,
 		Host:     c.apiURL.Host,
-		Path:     "/products/v1/buy-now",
+		Path:     path,
 		RawQuery: params.Encode(),
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", buyNowURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -78,26 +135,10 @@ func (c *Client) BuyNow(ctx context.Context, prod Product, country Country) ([]S
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("fetching stock data: %w", err)
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
 	}
 
-	var stockData []StockResponse
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	unescaped, err := strconv.Unquote(string(body))
-	if err != nil {
-		return nil, err
-	}
-
-	if err := json.Unmarshal([]byte(unescaped), &stockData); err != nil {
-		return nil, err
-	}
-
-	return stockData, nil
+	return io.ReadAll(resp.Body)
 }
 
 func WithHTTPClient(client *http.Client) Option {
@@ -1,30 +1,133 @@
 package nvidia
 
-type Country string
+import "time"
+
+type (
+	Country string
+
+	// Locale is the NVIDIA store of a country.
+	Locale struct {
+		Country Country
+		// Code is the locale of the store pages and the product search, e.g.
+		// "sv-se".
+		Code string
+		// API is the locale of the buy-now API, e.g. "se".
+		API string
+		// Currency is the ISO 4217 code of the prices, e.g. "SEK".
+		Currency string
+		// TimeZone is the IANA time zone of the country, of the east coast
+		// for the United States.
+		TimeZone string
+	}
+)
 
 var (
-	CountrySweden      = Country("Sweden")
-	CountryDenmark     = Country("Denmark")
-	CountryFinland     = Country("Finland")
-	CountryGermany     = Country("Germany")
-	CountryNetherlands = Country("Netherlands")
+	CountryAustria       = Country("Austria")
+	CountryBelgium       = Country("Belgium")
+	CountryBulgaria      = Country("Bulgaria")
+	CountryCroatia       = Country("Croatia")
+	CountryCyprus        = Country("Cyprus")
+	CountryCzechia       = Country("Czechia")
+	CountryDenmark       = Country("Denmark")
+	CountryEstonia       = Country("Estonia")
+	CountryFinland       = Country("Finland")
+	CountryFrance        = Country("France")
+	CountryGermany       = Country("Germany")
+	CountryGreece        = Country("Greece")
+	CountryHungary       = Country("Hungary")
+	CountryIreland       = Country("Ireland")
+	CountryItaly         = Country("Italy")
+	CountryLatvia        = Country("Latvia")
+	CountryLithuania     = Country("Lithuania")
+	CountryLuxembourg    = Country("Luxembourg")
+	CountryMalta         = Country("Malta")
+	CountryNetherlands   = Country("Netherlands")
+	CountryPoland        = Country("Poland")
+	CountryPortugal      = Country("Portugal")
+	CountryRomania       = Country("Romania")
+	CountrySlovakia      = Country("Slovakia")
+	CountrySlovenia      = Country("Slovenia")
+	CountrySpain         = Country("Spain")
+	CountrySweden        = Country("Sweden")
+	CountryUnitedKingdom = Country("United Kingdom")
+	CountryUnitedStates  = Country("United States")
 )
 
-func (c Country) Locale() string {
-	switch c {
-	case CountrySweden:
-		return "se"
-	case CountryDenmark:
-		return "dk"
-	case CountryFinland:
-		return "fi"
-	case CountryGermany:
-		return "de"
-	case CountryNetherlands:
-		return "nl"
-	default:
-		return ""
+// locales are the NVIDIA stores of the EU countries, the UK and the US.
+var locales = []Locale{
+	{CountryAustria, "de-at", "at", "EUR", "Europe/Vienna"},
+	{CountryBelgium, "fr-be", "be", "EUR", "Europe/Brussels"},
+	{CountryBulgaria, "bg-bg", "bg", "EUR", "Europe/Sofia"},
+	{CountryCroatia, "hr-hr", "hr", "EUR", "Europe/Zagreb"},
+	{CountryCyprus, "el-cy", "cy", "EUR", "Asia/Nicosia"},
+	{CountryCzechia, "cs-cz", "cz", "CZK", "Europe/Prague"},
+	{CountryDenmark, "da-dk", "dk", "DKK", "Europe/Copenhagen"},
+	{CountryEstonia, "et-ee", "ee", "EUR", "Europe/Tallinn"},
+	{CountryFinland, "fi-fi", "fi", "EUR", "Europe/Helsinki"},
+	{CountryFrance, "fr-fr", "fr", "EUR", "Europe/Paris"},
+	{CountryGermany, "de-de", "de", "EUR", "Europe/Berlin"},
+	{CountryGreece, "el-gr", "gr", "EUR", "Europe/Athens"},
+	{CountryHungary, "hu-hu", "hu", "HUF", "Europe/Budapest"},
+	{CountryIreland, "en-ie", "ie", "EUR", "Europe/Dublin"},
+	{CountryItaly, "it-it", "it", "EUR", "Europe/Rome"},
+	{CountryLatvia, "lv-lv", "lv", "EUR", "Europe/Riga"},
+	{CountryLithuania, "lt-lt", "lt", "EUR", "Europe/Vilnius"},
+	{CountryLuxembourg, "fr-lu", "lu", "EUR", "Europe/Luxembourg"},
+	{CountryMalta, "en-mt", "mt", "EUR", "Europe/Malta"},
+	{CountryNetherlands, "nl-nl", "nl", "EUR", "Europe/Amsterdam"},
+	{CountryPoland, "pl-pl", "pl", "PLN", "Europe/Warsaw"},
+	{CountryPortugal, "pt-pt", "pt", "EUR", "Europe/Lisbon"},
+	{CountryRomania, "ro-ro", "ro", "RON", "Europe/Bucharest"},
+	{CountrySlovakia, "sk-sk", "sk", "EUR", "Europe/Bratislava"},
+	{CountrySlovenia, "sl-si", "si", "EUR", "Europe/Ljubljana"},
+	{CountrySpain, "es-es", "es", "EUR", "Europe/Madrid"},
+	{CountrySweden, "sv-se", "se", "SEK", "Europe/Stockholm"},
+	{CountryUnitedKingdom, "en-gb", "uk", "GBP", "Europe/London"},
+	{CountryUnitedStates, "en-us", "us", "USD", "America/New_York"},
+}
+
+// Locales returns the NVIDIA stores ordered by country.
+func Locales() []Locale {
+	return append([]Locale(nil), locales...)
+}
+
+// Countries returns the countries of the NVIDIA stores.
+func Countries() []Country {
+	countries := make([]Country, 0, len(locales))
+
+	for _, l := range locales {
+		countries = append(countries, l.Country)
+	}
+
+	return countries
+}
+
+// LookupLocale returns the NVIDIA store of the country.
+func LookupLocale(c Country) (Locale, bool) {
+	for _, l := range locales {
+		if l.Country == c {
+			return l, true
+		}
 	}
+
+	return Locale{}, false
+}
+
+// Location returns the time zone of the country, UTC if it's unknown.
+func (l Locale) Location() *time.Location {
+	loc, err := time.LoadLocation(l.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// Locale returns the locale code of the NVIDIA store, empty if there is no
+// store in the country. The buy-now API uses the API locale instead.
+func (c Country) Locale() string {
+	l, _ := LookupLocale(c)
+	return l.Code
 }
 
 func (c Country) String() string {
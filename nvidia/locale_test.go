@@ -0,0 +1,41 @@
+package nvidia
+
+import (
+	"regexp"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+func TestLocales(t *testing.T) {
+	seen := make(map[Country]bool)
+
+	for _, c := range Countries() {
+		t.Run(string(c), func(t *testing.T) {
+			if seen[c] {
+				t.Errorf("%s has more than one locale", c)
+			}
+
+			seen[c] = true
+
+			l, ok := LookupLocale(c)
+			if !ok {
+				t.Fatalf("LookupLocale(%q) found no locale", c)
+			}
+
+			if c.Locale() == "" || l.API == "" {
+				t.Errorf("locale = %q, API locale = %q, want both", c.Locale(), l.API)
+			}
+
+			if !currencyCode.MatchString(l.Currency) {
+				t.Errorf("currency = %q, want an ISO 4217 code", l.Currency)
+			}
+
+			if _, err := time.LoadLocation(l.TimeZone); err != nil || l.TimeZone == "" {
+				t.Errorf("time zone %q: %v", l.TimeZone, err)
+			}
+		})
+	}
+}
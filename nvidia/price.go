@@ -0,0 +1,89 @@
+package nvidia
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Price is an offer price in the currency of the offer. Zero means the price
+// is unknown.
+type Price float64
+
+// UnmarshalJSON accepts both numbers and formatted strings such as
+// "2 399,00 kr" or "€2,399.00".
+func (p *Price) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*p = 0
+		return nil
+	}
+
+	var num float64
+
+	if err := json.Unmarshal(data, &num); err == nil {
+		*p = Price(num)
+		return nil
+	}
+
+	var str string
+
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("unmarshaling price: %w", err)
+	}
+
+	price, err := ParsePrice(str)
+	if err != nil {
+		return err
+	}
+
+	*p = price
+
+	return nil
+}
+
+// ParsePrice parses a formatted price. The last separator is the decimal one
+// unless it is followed by exactly three digits, other separators are grouping.
+func ParsePrice(s string) (Price, error) {
+	var digits strings.Builder
+
+	for _, r := range s {
+		if r >= '0' && r <= '9' || r == '.' || r == ',' {
+			digits.WriteRune(r)
+		}
+	}
+
+	num := strings.Trim(digits.String(), ".,")
+	if num == "" {
+		return 0, nil
+	}
+
+	decimal := -1
+
+	if i := strings.LastIndexAny(num, ".,"); i != -1 && len(num)-i-1 != 3 {
+		decimal = i
+	}
+
+	var normalized strings.Builder
+
+	for i, r := range num {
+		switch {
+		case i == decimal:
+			normalized.WriteRune('.')
+		case r != '.' && r != ',':
+			normalized.WriteRune(r)
+		}
+	}
+
+	amount, err := strconv.ParseFloat(normalized.String(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing price %q: %w", s, err)
+	}
+
+	return Price(amount), nil
+}
+
+func (p Price) String() string {
+	return strconv.FormatFloat(float64(p), 'f', 2, 64)
+}
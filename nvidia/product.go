@@ -1,16 +1,66 @@
 package nvidia
 
+import "sync"
+
 type (
 	Product string
 )
 
+var (
+	// discovered holds the SKU codes added to the catalog by country, they
+	// replace the built-in codes.
+	discovered   = make(map[Country]map[Product]string)
+	discoveredMu sync.RWMutex
+)
+
 var (
 	ProductRTX5090 = Product("RTX 5090 FE")
 	ProductRTX5080 = Product("RTX 5080 FE")
 	ProductRTX4070 = Product("RTX 4070 FE")
 )
 
+// AddSKU adds the SKU code of the product in the country to the catalog,
+// e.g. a code found by the product search.
+func AddSKU(p Product, c Country, sku string) {
+	discoveredMu.Lock()
+	defer discoveredMu.Unlock()
+
+	if discovered[c] == nil {
+		discovered[c] = make(map[Product]string)
+	}
+
+	discovered[c][p] = sku
+}
+
+// Catalog is the catalog of Product.SKU as a value, e.g. to inject it into
+// the discovery.
+type Catalog struct{}
+
+// SKU returns the SKU code of the product in the country, see Product.SKU.
+func (Catalog) SKU(p Product, c Country) string {
+	return p.SKU(c)
+}
+
+// AddSKU adds the SKU code of the product in the country, see AddSKU.
+func (Catalog) AddSKU(p Product, c Country, sku string) {
+	AddSKU(p, c, sku)
+}
+
+// SKU returns the SKU code of the product in the country, empty if the
+// product isn't sold there.
 func (p Product) SKU(c Country) string {
+	discoveredMu.RLock()
+	sku, ok := discovered[c][p]
+	discoveredMu.RUnlock()
+
+	if ok {
+		return sku
+	}
+
+	return p.builtinSKU(c)
+}
+
+func (p Product) builtinSKU(c Country) string {
 	switch c {
 	case CountrySweden:
 		switch p {
@@ -0,0 +1,84 @@
+package nvidia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// searchLimit is the number of products requested from the search, more than
+// the store lists per locale.
+const searchLimit = 100
+
+type (
+	// ProductDetails is a product listed by the NVIDIA store.
+	ProductDetails struct {
+		ProductTitle     string `json:"productTitle"`
+		DisplayName      string `json:"displayName"`
+		ProductSKU       string `json:"productSKU"`
+		ProductUPC       string `json:"productUPC"`
+		GPU              string `json:"gpu"`
+		IsFounderEdition bool   `json:"isFounderEdition"`
+		Status           string `json:"prdStatus"`
+	}
+
+	searchResponse struct {
+		SearchedProducts struct {
+			FeaturedProduct *ProductDetails  `json:"featuredProduct"`
+			ProductDetails  []ProductDetails `json:"productDetails"`
+		} `json:"searchedProducts"`
+	}
+)
+
+// SearchProducts returns the graphics cards listed by the NVIDIA store in the
+// store locale, e.g. "sv-se".
+func (c *Client) SearchProducts(ctx context.Context, locale string) ([]ProductDetails, error) {
+	params := make(url.Values)
+
+	params.Set("page", "1")
+	params.Set("limit", strconv.Itoa(searchLimit))
+	params.Set("locale", locale)
+	params.Set("category", "GPU")
+	params.Set("manufacturer", "NVIDIA")
+
+	body, err := c.get(ctx, "/edge/product/search", params)
+	if err != nil {
+		return nil, fmt.Errorf("searching products: %w", err)
+	}
+
+	var resp searchResponse
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("decoding products: %w", err)
+	}
+
+	products := resp.SearchedProducts.ProductDetails
+
+	// The featured product is usually listed again.
+	if f := resp.SearchedProducts.FeaturedProduct; f != nil && f.ProductSKU != "" {
+		for _, p := range products {
+			if p.ProductSKU == f.ProductSKU {
+				return products, nil
+			}
+		}
+
+		products = append(products, *f)
+	}
+
+	return products, nil
+}
+
+// FounderEdition returns the name of the Founders Edition card of the GPU
+// listed by the store, e.g. "RTX 5090 FE" for "NVIDIA GeForce RTX 5090".
+func FounderEdition(gpu string) Product {
+	name := strings.TrimSpace(gpu)
+
+	for _, prefix := range []string{"NVIDIA ", "GeForce "} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+
+	return Product(strings.TrimSpace(name) + " FE")
+}
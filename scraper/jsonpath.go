@@ -0,0 +1,274 @@
+package scraper
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	// jsonPath is a compiled JSONPath expression.
+	jsonPath []pathStep
+
+	pathStep struct {
+		kind stepKind
+		// name is the member of stepChild and stepDescendant.
+		name string
+		// index is the array index of stepIndex, negative from the end.
+		index int
+		// filter and value select the array elements or object members whose
+		// filter path equals the value for stepFilter.
+		filter jsonPath
+		value  string
+	}
+
+	stepKind int
+)
+
+const (
+	stepChild stepKind = iota
+	stepDescendant
+	stepWildcard
+	stepIndex
+	stepFilter
+)
+
+// compileJSONPath parses the subset of JSONPath used by the scraper: the root
+// "$", members ".name" and "['name']", recursive descent "..name", wildcards
+// ".*" and "[*]", indexes "[0]" and "[-1]", and equality filters such as
+// "[?(@.seller.name=='Shop')]".
+func compileJSONPath(s string) (jsonPath, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(s), "$")
+	if !ok {
+		return nil, fmt.Errorf("parsing JSONPath %q: must start with $", s)
+	}
+
+	path, err := compileSteps(rest)
+	if err != nil {
+		return nil, fmt.Errorf("parsing JSONPath %q: %w", s, err)
+	}
+
+	return path, nil
+}
+
+func compileSteps(s string) (jsonPath, error) {
+	var path jsonPath
+
+	for s != "" {
+		var (
+			step pathStep
+			err  error
+		)
+
+		switch {
+		case strings.HasPrefix(s, ".."):
+			step.kind = stepDescendant
+			step.name, s = readMember(s[2:])
+
+			if step.name == "" {
+				return nil, fmt.Errorf("missing member after ..")
+			}
+		case strings.HasPrefix(s, ".*"):
+			step.kind, s = stepWildcard, s[2:]
+		case strings.HasPrefix(s, "."):
+			step.kind = stepChild
+			step.name, s = readMember(s[1:])
+
+			if step.name == "" {
+				return nil, fmt.Errorf("missing member after .")
+			}
+		case strings.HasPrefix(s, "["):
+			step, s, err = compileBracket(s)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q", s)
+		}
+
+		path = append(path, step)
+	}
+
+	return path, nil
+}
+
+func compileBracket(s string) (pathStep, string, error) {
+	var step pathStep
+
+	if strings.HasPrefix(s, "[?(") {
+		end := strings.Index(s, ")]")
+		if end < 0 {
+			return step, s, fmt.Errorf("unterminated filter")
+		}
+
+		lhs, rhs, ok := strings.Cut(s[3:end], "==")
+		lhs, rhs = strings.TrimSpace(lhs), strings.TrimSpace(rhs)
+
+		filterPath, found := strings.CutPrefix(lhs, "@")
+		if !ok || !found {
+			return step, s, fmt.Errorf("unsupported filter %q, expected @.path=='value'", s[:end+2])
+		}
+
+		filter, err := compileSteps(filterPath)
+		if err != nil {
+			return step, s, err
+		}
+
+		step.kind = stepFilter
+		step.filter = filter
+		step.value = unquote(rhs)
+
+		return step, s[end+2:], nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return step, s, fmt.Errorf("unterminated bracket")
+	}
+
+	inner := strings.TrimSpace(s[1:end])
+
+	switch {
+	case inner == "*":
+		step.kind = stepWildcard
+	case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"'):
+		step.kind = stepChild
+		step.name = unquote(inner)
+	default:
+		index, err := strconv.Atoi(inner)
+		if err != nil {
+			return step, s, fmt.Errorf("invalid index %q", inner)
+		}
+
+		step.kind = stepIndex
+		step.index = index
+	}
+
+	return step, s[end+1:], nil
+}
+
+// readMember reads a dot notation member name, which ends at the next dot or
+// bracket.
+func readMember(s string) (string, string) {
+	i := strings.IndexAny(s, ".[")
+	if i < 0 {
+		return s, ""
+	}
+
+	return s[:i], s[i:]
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
+
+// eval returns the values matching the path in the decoded JSON document.
+func (p jsonPath) eval(doc any) []any {
+	nodes := []any{doc}
+
+	for _, step := range p {
+		var next []any
+
+		for _, n := range nodes {
+			next = step.apply(n, next)
+		}
+
+		nodes = next
+	}
+
+	return nodes
+}
+
+// apply appends the values selected by the step from the node to out.
+func (step pathStep) apply(n any, out []any) []any {
+	switch step.kind {
+	case stepChild:
+		if obj, ok := n.(map[string]any); ok {
+			if v, ok := obj[step.name]; ok {
+				out = append(out, v)
+			}
+		}
+	case stepDescendant:
+		out = descendants(n, step.name, out)
+	case stepWildcard:
+		out = append(out, children(n)...)
+	case stepIndex:
+		if arr, ok := n.([]any); ok {
+			i := step.index
+			if i < 0 {
+				i += len(arr)
+			}
+
+			if i >= 0 && i < len(arr) {
+				out = append(out, arr[i])
+			}
+		}
+	case stepFilter:
+		for _, c := range children(n) {
+			if slices.ContainsFunc(step.filter.eval(c), func(v any) bool {
+				s, ok := scalar(v)
+				return ok && s == step.value
+			}) {
+				out = append(out, c)
+			}
+		}
+	}
+
+	return out
+}
+
+// descendants appends the members named name of the node and of all its
+// descendants to out.
+func descendants(n any, name string, out []any) []any {
+	if obj, ok := n.(map[string]any); ok {
+		if v, ok := obj[name]; ok {
+			out = append(out, v)
+		}
+	}
+
+	for _, c := range children(n) {
+		out = descendants(c, name, out)
+	}
+
+	return out
+}
+
+// children returns the elements of arrays and the member values of objects.
+// Members are ordered by name, since decoded objects are unordered.
+func children(n any) []any {
+	switch v := n.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		values := make([]any, 0, len(v))
+
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			values = append(values, v[k])
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+// scalar formats strings, numbers and booleans, it fails for null, arrays
+// and objects.
+func scalar(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
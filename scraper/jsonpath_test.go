@@ -0,0 +1,86 @@
+package scraper
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileJSONPath(t *testing.T) {
+	const doc = `{
+		"name": "RTX 5090",
+		"tags": ["gpu", "nvidia", "5090"],
+		"offers": [
+			{"seller": {"name": "Shop A"}, "price": 2399, "inStock": false},
+			{"seller": {"name": "Shop B"}, "price": 2499.5, "inStock": true},
+			{"seller": {"name": "Shop B"}, "price": 2599, "variant": {"price": 99}}
+		],
+		"meta": {"price": "n/a", "'quoted'": 1}
+	}`
+
+	var v any
+
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    []any
+		wantErr string
+	}{
+		{path: "$", want: []any{v}},
+		{path: "$.name", want: []any{"RTX 5090"}},
+		{path: " $['name'] ", want: []any{"RTX 5090"}},
+		{path: `$["name"]`, want: []any{"RTX 5090"}},
+		{path: "$.missing"},
+		{path: "$.name.missing"},
+		{path: "$.tags[0]", want: []any{"gpu"}},
+		{path: "$.tags[-1]", want: []any{"5090"}},
+		{path: "$.tags[3]"},
+		{path: "$.tags[-4]"},
+		{path: "$.tags[*]", want: []any{"gpu", "nvidia", "5090"}},
+		{path: "$.tags.*", want: []any{"gpu", "nvidia", "5090"}},
+		{path: "$.offers[1].seller.name", want: []any{"Shop B"}},
+		// Descendants are found in document order, object members sorted
+		// by name.
+		{path: "$..price", want: []any{"n/a", 2399.0, 2499.5, 2599.0, 99.0}},
+		{path: "$.offers..price", want: []any{2399.0, 2499.5, 2599.0, 99.0}},
+		{path: "$.offers[*].price", want: []any{2399.0, 2499.5, 2599.0}},
+		{path: "$.offers[?(@.seller.name=='Shop B')].price", want: []any{2499.5, 2599.0}},
+		{path: `$.offers[?(@.seller.name == "Shop A")].price`, want: []any{2399.0}},
+		{path: "$.offers[?(@.inStock=='true')].price", want: []any{2499.5}},
+		{path: "$.offers[?(@.seller.name=='Shop C')].price"},
+		{path: "name", wantErr: "must start with $"},
+		{path: "$.", wantErr: "missing member after ."},
+		{path: "$..", wantErr: "missing member after .."},
+		{path: "$name", wantErr: `unexpected "name"`},
+		{path: "$.tags[0", wantErr: "unterminated bracket"},
+		{path: "$.tags[first]", wantErr: `invalid index "first"`},
+		{path: "$.offers[?(@.price>1)]", wantErr: "unsupported filter"},
+		{path: "$.offers[?(price=='1')]", wantErr: "unsupported filter"},
+		{path: "$.offers[?(@.price=='1']", wantErr: "unterminated filter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := compileJSONPath(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("compileJSONPath() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("compileJSONPath() error = %v", err)
+			}
+
+			if got := path.eval(v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+type (
+	// Rule extracts a value from a product page. HTML pages are queried with
+	// the Selector, JSON pages with the Path and other pages with the Match
+	// expression alone.
+	Rule struct {
+		// Selector is a CSS selector of the HTML element holding the value.
+		Selector string `json:"selector,omitempty"`
+		// Attr takes the value from the attribute of the element instead of
+		// its text, e.g. "content" of a meta element.
+		Attr string `json:"attr,omitempty"`
+		// Path is a JSONPath into a JSON page, or into the JSON text of the
+		// elements matched by the Selector such as JSON-LD scripts.
+		Path string `json:"path,omitempty"`
+		// Match is a regular expression the value must match, or the page
+		// without Selector and Path. The first group becomes the value if
+		// the expression has groups.
+		Match string `json:"match,omitempty"`
+	}
+
+	// Rules extract the offer from a product page.
+	Rules struct {
+		// Available finds a value if the product is in stock, e.g. the
+		// schema.org availability matching "InStock".
+		Available Rule `json:"available"`
+		// Stock is the number of items in stock, 1 if not set.
+		Stock Rule `json:"stock"`
+		Price Rule `json:"price"`
+		// Currency of the price, the currency of the retailer if not set.
+		Currency Rule `json:"currency"`
+		// Title of the product, the product name if not set.
+		Title Rule `json:"title"`
+		// Link to buy the product, the page itself if not set.
+		Link Rule `json:"link"`
+	}
+
+	rule struct {
+		sel   selector
+		attr  string
+		path  jsonPath
+		match *regexp.Regexp
+	}
+
+	compiledRules struct {
+		available, stock, price, currency, title, link *rule
+	}
+
+	// page is a fetched product page, parsed on demand.
+	page struct {
+		body    []byte
+		doc     *html.Node
+		docErr  error
+		json    any
+		jsonErr error
+		parsed  struct{ doc, json bool }
+	}
+)
+
+// presets are rules of common page formats.
+var presets = map[string]Rules{
+	// jsonld reads the schema.org Product markup most shops embed for search
+	// engines.
+	"jsonld": {
+		Available: Rule{
+			Selector: `script[type="application/ld+json"]`,
+			Path:     "$..availability",
+			Match:    "(InStock|LimitedAvailability|OnlineOnly)$",
+		},
+		Price: Rule{
+			Selector: `script[type="application/ld+json"]`,
+			Path:     "$..offers..price",
+		},
+		Currency: Rule{
+			Selector: `script[type="application/ld+json"]`,
+			Path:     "$..offers..priceCurrency",
+		},
+		Title: Rule{
+			Selector: `meta[property="og:title"]`,
+			Attr:     "content",
+		},
+	},
+}
+
+func (r Rule) isZero() bool {
+	return r == Rule{}
+}
+
+// compile returns nil for unset rules.
+func (r Rule) compile() (*rule, error) {
+	if r.isZero() {
+		return nil, nil
+	}
+
+	if r.Selector == "" && r.Path == "" && r.Match == "" {
+		return nil, fmt.Errorf("rule needs a selector, a path or a match")
+	}
+
+	if r.Attr != "" && r.Selector == "" {
+		return nil, fmt.Errorf("attr %q needs a selector", r.Attr)
+	}
+
+	var (
+		c   = rule{attr: r.Attr}
+		err error
+	)
+
+	if r.Selector != "" {
+		if c.sel, err = compileSelector(r.Selector); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Path != "" {
+		if c.path, err = compileJSONPath(r.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Match != "" {
+		if c.match, err = regexp.Compile(r.Match); err != nil {
+			return nil, fmt.Errorf("parsing match: %w", err)
+		}
+	}
+
+	return &c, nil
+}
+
+// withPreset fills the unset rules from the preset.
+func (r Rules) withPreset(name string) (Rules, error) {
+	if name == "" {
+		return r, nil
+	}
+
+	preset, ok := presets[name]
+	if !ok {
+		return r, fmt.Errorf("unknown preset %q", name)
+	}
+
+	for _, pair := range []struct{ rule, preset *Rule }{
+		{&r.Available, &preset.Available},
+		{&r.Stock, &preset.Stock},
+		{&r.Price, &preset.Price},
+		{&r.Currency, &preset.Currency},
+		{&r.Title, &preset.Title},
+		{&r.Link, &preset.Link},
+	} {
+		if pair.rule.isZero() {
+			*pair.rule = *pair.preset
+		}
+	}
+
+	return r, nil
+}
+
+func (r Rules) compile() (compiledRules, error) {
+	var c compiledRules
+
+	if r.Available.isZero() {
+		return c, fmt.Errorf("missing available rule")
+	}
+
+	for _, field := range []struct {
+		name string
+		rule Rule
+		dst  **rule
+	}{
+		{"available", r.Available, &c.available},
+		{"stock", r.Stock, &c.stock},
+		{"price", r.Price, &c.price},
+		{"currency", r.Currency, &c.currency},
+		{"title", r.Title, &c.title},
+		{"link", r.Link, &c.link},
+	} {
+		compiled, err := field.rule.compile()
+		if err != nil {
+			return c, fmt.Errorf("%s rule: %w", field.name, err)
+		}
+
+		*field.dst = compiled
+	}
+
+	return c, nil
+}
+
+// extract returns the first value found by the rule, false if there is none.
+// It fails if the page can't be parsed as needed by the rule.
+func (r *rule) extract(p *page) (string, bool, error) {
+	if r.sel == nil && r.path == nil {
+		value, ok := r.matchBody(p.body)
+		return value, ok, nil
+	}
+
+	if r.sel == nil {
+		doc, err := p.parseJSON()
+		if err != nil {
+			return "", false, err
+		}
+
+		value, ok := r.extractJSON(doc)
+
+		return value, ok, nil
+	}
+
+	doc, err := p.parseHTML()
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, n := range r.sel.all(doc) {
+		if r.path == nil {
+			value := attr(n, r.attr)
+			if r.attr == "" {
+				value = strings.Join(strings.Fields(text(n)), " ")
+			}
+
+			if value, ok := r.matchValue(value); ok {
+				return value, true, nil
+			}
+
+			continue
+		}
+
+		// Skip elements without valid JSON, there may be others.
+		var embedded any
+
+		if err := json.Unmarshal([]byte(text(n)), &embedded); err != nil {
+			continue
+		}
+
+		if value, ok := r.extractJSON(embedded); ok {
+			return value, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+func (r *rule) extractJSON(doc any) (string, bool) {
+	for _, v := range r.path.eval(doc) {
+		value, ok := scalar(v)
+		if !ok {
+			continue
+		}
+
+		if value, ok := r.matchValue(value); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// matchValue applies the match expression to the value, empty values never
+// match.
+func (r *rule) matchValue(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+
+	if r.match != nil {
+		m := r.match.FindStringSubmatch(value)
+		if m == nil {
+			return "", false
+		}
+
+		if len(m) > 1 {
+			value = m[1]
+		}
+	}
+
+	return value, value != ""
+}
+
+// matchBody applies the match expression to the whole page, the value is the
+// first group or the match itself.
+func (r *rule) matchBody(body []byte) (string, bool) {
+	m := r.match.FindSubmatch(body)
+	if m == nil {
+		return "", false
+	}
+
+	value := m[0]
+	if len(m) > 1 {
+		value = m[1]
+	}
+
+	trimmed := strings.TrimSpace(string(value))
+
+	return trimmed, trimmed != ""
+}
+
+func (p *page) parseHTML() (*html.Node, error) {
+	if !p.parsed.doc {
+		p.parsed.doc = true
+		p.doc, p.docErr = html.Parse(bytes.NewReader(p.body))
+	}
+
+	if p.docErr != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", p.docErr)
+	}
+
+	return p.doc, nil
+}
+
+func (p *page) parseJSON() (any, error) {
+	if !p.parsed.json {
+		p.parsed.json = true
+		p.jsonErr = json.Unmarshal(p.body, &p.json)
+	}
+
+	if p.jsonErr != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", p.jsonErr)
+	}
+
+	return p.json, nil
+}
@@ -0,0 +1,380 @@
+// Package scraper checks the stock of retailers without an API by extracting
+// the offers from their product pages.
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+)
+
+// maxPageSize limits the size of fetched product pages.
+const maxPageSize = 10 << 20
+
+type (
+	// Retailer describes the product pages of a retailer and how to read
+	// them.
+	Retailer struct {
+		Name string `json:"name"`
+		// Currency of the prices if the pages don't state it.
+		Currency string `json:"currency,omitempty"`
+		// Preset provides the rules not set in Rules, e.g. "jsonld" for
+		// pages with schema.org Product markup.
+		Preset string `json:"preset,omitempty"`
+		Rules  Rules  `json:"rules"`
+		// Request fetches the pages, e.g. a search API of the retailer.
+		Request Request `json:"request"`
+		Pages   []Page  `json:"pages"`
+	}
+
+	// Request describes the HTTP request of a page. URL and Body are
+	// text/template templates executed with the PageData.
+	Request struct {
+		// Method is GET by default.
+		Method  string            `json:"method,omitempty"`
+		URL     string            `json:"url,omitempty"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    string            `json:"body,omitempty"`
+	}
+
+	// Page is the product page of a product in a country.
+	Page struct {
+		Product string `json:"product"`
+		Country string `json:"country"`
+		// URL replaces the URL template of the Request.
+		URL string `json:"url,omitempty"`
+		// Link is shown to users instead of the URL, e.g. if the URL is an
+		// API endpoint.
+		Link string `json:"link,omitempty"`
+		// Params are passed to the templates, e.g. the product ID of the
+		// retailer.
+		Params map[string]string `json:"params,omitempty"`
+	}
+
+	// PageData is the data of the Request templates.
+	PageData struct {
+		Product string
+		Country string
+		// Locale is the NVIDIA locale and SKU the NVIDIA SKU code of the
+		// product in the country, if any.
+		Locale string
+		SKU    string
+		Params map[string]string
+	}
+
+	// Scraper implements monitor.StockSource for the products of a retailer.
+	Scraper struct {
+		retailer Retailer
+		rules    compiledRules
+		pages    map[pageKey]pageRequest
+		client   *http.Client
+	}
+
+	Option func(*Scraper)
+
+	pageKey struct {
+		product string
+		country string
+	}
+
+	// pageRequest is the Request of a page with the templates executed.
+	pageRequest struct {
+		method string
+		url    string
+		body   string
+		link   string
+	}
+)
+
+// LoadRetailers reads a JSON array of retailers from the file.
+func LoadRetailers(name string) ([]Retailer, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var retailers []Retailer
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&retailers); err != nil {
+		return nil, fmt.Errorf("decoding retailers: %w", err)
+	}
+
+	return retailers, nil
+}
+
+func New(r Retailer, opts ...Option) (*Scraper, error) {
+	if r.Name == "" {
+		return nil, fmt.Errorf("retailer without name")
+	}
+
+	rules, err := r.Rules.withPreset(r.Preset)
+	if err != nil {
+		return nil, fmt.Errorf("retailer %s: %w", r.Name, err)
+	}
+
+	s := Scraper{
+		retailer: r,
+		pages:    make(map[pageKey]pageRequest),
+	}
+
+	if s.rules, err = rules.compile(); err != nil {
+		return nil, fmt.Errorf("retailer %s: %w", r.Name, err)
+	}
+
+	for _, p := range r.Pages {
+		if p.Product == "" || p.Country == "" {
+			return nil, fmt.Errorf("retailer %s: page without product or country", r.Name)
+		}
+
+		req, err := r.Request.resolve(p)
+		if err != nil {
+			return nil, fmt.Errorf("retailer %s: page of %s in %s: %w", r.Name, p.Product, p.Country, err)
+		}
+
+		s.pages[pageKey{p.Product, p.Country}] = req
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+
+	return &s, nil
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Scraper) {
+		s.client = client
+	}
+}
+
+// Name returns the name of the retailer.
+func (s *Scraper) Name() string {
+	return s.retailer.Name
+}
+
+// Offers reports whether the retailer has a page of the product in the
+// country.
+func (s *Scraper) Offers(product, country string) bool {
+	_, ok := s.pages[pageKey{product, country}]
+	return ok
+}
+
+// Check implements monitor.StockSource. It returns no offers if the product
+// isn't available.
+func (s *Scraper) Check(ctx context.Context, product, country string) ([]monitor.Offer, error) {
+	page, ok := s.pages[pageKey{product, country}]
+	if !ok {
+		return nil, fmt.Errorf("%s has no page of %s in %s", s.retailer.Name, product, country)
+	}
+
+	var body io.Reader
+
+	if page.body != "" {
+		body = strings.NewReader(page.body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, page.method, page.url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("user-agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Safari/537.36")
+
+	for name, value := range s.retailer.Request.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", page.url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Parse(product, page.link, data)
+}
+
+// Parse extracts the offer of the product from the page, e.g. a recorded
+// fixture. Offers link to the page at link unless the rules find a link.
+func (s *Scraper) Parse(product, link string, body []byte) ([]monitor.Offer, error) {
+	p := page{body: body}
+
+	if _, ok, err := s.rules.available.extract(&p); err != nil || !ok {
+		return nil, err
+	}
+
+	offer := monitor.Offer{
+		Title:    product,
+		Retailer: s.retailer.Name,
+		Stock:    1,
+		Currency: s.retailer.Currency,
+		Link:     link,
+		PageLink: link,
+	}
+
+	if value, ok, err := extract(s.rules.stock, &p); err != nil {
+		return nil, err
+	} else if digits := strings.Map(digitsOnly, value); ok && digits != "" {
+		stock, err := strconv.Atoi(digits)
+		if err != nil {
+			return nil, fmt.Errorf("parsing stock %q: %w", value, err)
+		}
+
+		// The product is available, even if the page shows no count.
+		offer.Stock = max(stock, 1)
+	}
+
+	if value, ok, err := extract(s.rules.price, &p); err != nil {
+		return nil, err
+	} else if ok {
+		if offer.Price, err = nvidia.ParsePrice(value); err != nil {
+			return nil, err
+		}
+	}
+
+	if value, ok, err := extract(s.rules.currency, &p); err != nil {
+		return nil, err
+	} else if ok {
+		offer.Currency = value
+	}
+
+	if value, ok, err := extract(s.rules.title, &p); err != nil {
+		return nil, err
+	} else if ok {
+		offer.Title = value
+	}
+
+	if value, ok, err := extract(s.rules.link, &p); err != nil {
+		return nil, err
+	} else if ok {
+		offer.Link = resolve(link, value)
+	}
+
+	return []monitor.Offer{offer}, nil
+}
+
+// resolve executes the templates of the request for the page.
+func (r Request) resolve(p Page) (pageRequest, error) {
+	req := pageRequest{
+		method: strings.ToUpper(r.Method),
+		url:    r.URL,
+		link:   p.Link,
+	}
+
+	if req.method == "" {
+		req.method = http.MethodGet
+	}
+
+	if p.URL != "" {
+		req.url = p.URL
+	}
+
+	if req.url == "" {
+		return req, fmt.Errorf("missing URL")
+	}
+
+	data := PageData{
+		Product: p.Product,
+		Country: p.Country,
+		Locale:  nvidia.Country(p.Country).Locale(),
+		SKU:     nvidia.Product(p.Product).SKU(nvidia.Country(p.Country)),
+		Params:  p.Params,
+	}
+
+	var err error
+
+	if req.url, err = execute("url", req.url, data); err != nil {
+		return req, err
+	}
+
+	if u, err := url.Parse(req.url); err != nil || u.Scheme != "http" && u.Scheme != "https" {
+		return req, fmt.Errorf("invalid URL %q", req.url)
+	}
+
+	if req.body, err = execute("body", r.Body, data); err != nil {
+		return req, err
+	}
+
+	if req.link == "" {
+		req.link = req.url
+	}
+
+	return req, nil
+}
+
+// execute executes the template text with the data. Missing params fail
+// instead of resulting in "<no value>".
+func execute(name, text string, data PageData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s template: %w", name, err)
+	}
+
+	var sb strings.Builder
+
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("executing %s template: %w", name, err)
+	}
+
+	return sb.String(), nil
+}
+
+// extract is a no-op for unset rules.
+func extract(r *rule, p *page) (string, bool, error) {
+	if r == nil {
+		return "", false, nil
+	}
+
+	return r.extract(p)
+}
+
+// resolve returns the link relative to the page as an absolute URL.
+func resolve(pageURL, link string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return link
+	}
+
+	ref, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+func digitsOnly(r rune) rune {
+	if r >= '0' && r <= '9' {
+		return r
+	}
+
+	return -1
+}
@@ -0,0 +1,347 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/monitor/monitortest"
+)
+
+// apiOffer is the offer of the Example API seller in testdata/api.json.
+const apiOffer = "$.offers[?(@.seller.name=='Example API')]"
+
+var (
+	jsonldRetailer = Retailer{
+		Name:     "Example Shop",
+		Currency: "EUR",
+		Preset:   "jsonld",
+		Rules: Rules{
+			Link: Rule{Selector: "a.add-to-cart", Attr: "href"},
+		},
+	}
+
+	attrsRetailer = Retailer{
+		Name: "Example Store",
+		Rules: Rules{
+			Available: Rule{Selector: "#product .stock[data-status]", Attr: "data-status", Match: "^in-stock$"},
+			Stock:     Rule{Selector: "div.product > .stock-status > span.stock", Match: `(\d+)`},
+			Price:     Rule{Selector: "#product span.price[content]", Attr: "content"},
+			Currency:  Rule{Selector: `meta[itemprop="priceCurrency"]`, Attr: "content"},
+			Title:     Rule{Selector: "h1.product-title"},
+			Link:      Rule{Selector: `a.buy[href^="https"]`, Attr: "href"},
+		},
+	}
+
+	apiRetailer = Retailer{
+		Name:     "Example API",
+		Currency: "SEK",
+		Rules: Rules{
+			Available: Rule{Path: apiOffer + ".inStock", Match: "true"},
+			Stock:     Rule{Path: apiOffer + ".quantity"},
+			Price:     Rule{Path: apiOffer + ".price"},
+			Title:     Rule{Path: "$.product.name"},
+			Link:      Rule{Path: apiOffer + ".url"},
+		},
+	}
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		retailer Retailer
+		fixture  string
+		link     string
+		want     []monitor.Offer
+		wantErr  string
+	}{
+		{
+			name:     "jsonld",
+			retailer: jsonldRetailer,
+			fixture:  "jsonld.html",
+			link:     "https://shop.example/asus-rog-astral-5090",
+			want: []monitor.Offer{{
+				Title:    "ASUS ROG Astral GeForce RTX 5090 OC 32GB",
+				Retailer: "Example Shop",
+				Stock:    1,
+				Price:    3149,
+				Currency: "EUR",
+				Link:     "https://shop.example/cart/add?sku=90YV0LW0-M0NA00",
+				PageLink: "https://shop.example/asus-rog-astral-5090",
+			}},
+		},
+		{
+			name:     "jsonld out of stock",
+			retailer: jsonldRetailer,
+			fixture:  "jsonld_out_of_stock.html",
+			link:     "https://shop.example/asus-rog-astral-5090",
+		},
+		{
+			name:     "selectors and attributes",
+			retailer: attrsRetailer,
+			fixture:  "attrs.html",
+			link:     "https://shop.example/msi-5090-suprim",
+			want: []monitor.Offer{{
+				Title:    "MSI GeForce RTX 5090 Suprim Liquid SOC",
+				Retailer: "Example Store",
+				Stock:    12,
+				Price:    32990,
+				Currency: "SEK",
+				Link:     "https://shop.example/cart?add=MSI-5090-SUPRIM",
+				PageLink: "https://shop.example/msi-5090-suprim",
+			}},
+		},
+		{
+			name: "selector out of stock",
+			retailer: Retailer{
+				Name: "Example Store",
+				Rules: Rules{
+					Available: Rule{Selector: "aside .stock", Attr: "data-status", Match: "^in-stock$"},
+				},
+			},
+			fixture: "attrs.html",
+			link:    "https://shop.example/msi-5090-suprim",
+		},
+		{
+			name:     "JSON API",
+			retailer: apiRetailer,
+			fixture:  "api.json",
+			link:     "https://shop.example/12345",
+			want: []monitor.Offer{{
+				Title:    "Gigabyte GeForce RTX 5080 Gaming OC 16G",
+				Retailer: "Example API",
+				Stock:    4,
+				Price:    14490.5,
+				Currency: "SEK",
+				Link:     "https://shop.example/p/12345?seller=example",
+				PageLink: "https://shop.example/12345",
+			}},
+		},
+		{
+			name:     "JSON API out of stock",
+			retailer: apiRetailer,
+			fixture:  "api_out_of_stock.json",
+			link:     "https://shop.example/12345",
+		},
+		{
+			name: "match only",
+			retailer: Retailer{
+				Name:     "Example API",
+				Currency: "SEK",
+				Rules: Rules{
+					Available: Rule{Match: `"inStock": true`},
+					Stock:     Rule{Match: `"quantity": (\d+)`},
+				},
+			},
+			fixture: "api.json",
+			link:    "https://shop.example/12345",
+			want: []monitor.Offer{{
+				Title:    "Gigabyte RTX 5080",
+				Retailer: "Example API",
+				Stock:    1,
+				Currency: "SEK",
+				Link:     "https://shop.example/12345",
+				PageLink: "https://shop.example/12345",
+			}},
+		},
+		{
+			name: "path on HTML page",
+			retailer: Retailer{
+				Name:  "Example Store",
+				Rules: Rules{Available: Rule{Path: "$.inStock"}},
+			},
+			fixture: "attrs.html",
+			wantErr: "parsing JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.retailer)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			body, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The title falls back to the product name.
+			product := "Gigabyte RTX 5080"
+
+			got, err := s.Parse(product, tt.link, body)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	var gotBody string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+
+		if r.Method != http.MethodPost || r.URL.Path != "/stock" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		http.ServeFile(w, r, filepath.Join("testdata", "api.json"))
+	}))
+	defer srv.Close()
+
+	r := apiRetailer
+	r.Request = Request{
+		Method:  "post",
+		URL:     srv.URL + "/stock",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    `{"id": "{{.Params.id}}", "country": "{{.Country}}"}`,
+	}
+	r.Pages = []Page{
+		{Product: "Gigabyte RTX 5080", Country: "Sweden", Params: map[string]string{"id": "12345"}, Link: "https://shop.example/12345"},
+	}
+
+	s, err := New(r, WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if !s.Offers("Gigabyte RTX 5080", "Sweden") || s.Offers("Gigabyte RTX 5080", "Germany") {
+		t.Error("Offers() doesn't match the pages")
+	}
+
+	offers, err := s.Check(context.Background(), "Gigabyte RTX 5080", "Sweden")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if want := `{"id": "12345", "country": "Sweden"}`; gotBody != want {
+		t.Errorf("request body = %s, want %s", gotBody, want)
+	}
+
+	if len(offers) != 1 || offers[0].Stock != 4 || offers[0].Link != "https://shop.example/p/12345?seller=example" {
+		t.Errorf("Check() = %+v", offers)
+	}
+
+	if _, err := s.Check(context.Background(), "Gigabyte RTX 5080", "Germany"); err == nil {
+		t.Error("Check() of a product without page succeeded")
+	}
+}
+
+// catalogSource is a fallback offering the products of the catalog.
+type catalogSource struct {
+	*monitortest.Source
+	catalog map[string]bool
+}
+
+func (s catalogSource) Offers(product, country string) bool {
+	return s.catalog[product+"/"+country]
+}
+
+func TestSourcesCheck(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer srv.Close()
+
+	shop := jsonldRetailer
+	shop.Pages = []Page{
+		{Product: "RTX 5090 FE", Country: "Germany", URL: srv.URL + "/jsonld.html"},
+		{Product: "ASUS ROG Astral RTX 5090", Country: "Germany", URL: srv.URL + "/jsonld.html"},
+		{Product: "ASUS ROG Astral RTX 5090", Country: "France", URL: srv.URL + "/missing.html"},
+	}
+
+	s, err := New(shop, WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	fallback := catalogSource{
+		Source:  monitortest.NewSource(),
+		catalog: map[string]bool{"RTX 5090 FE/Germany": true, "RTX 5080 FE/Germany": true},
+	}
+
+	nvidiaOffer := monitor.Offer{Retailer: "NVIDIA", Stock: 1, Price: 2329, Currency: "EUR"}
+	fallback.Set("RTX 5090 FE", "Germany", nvidiaOffer)
+	fallback.Set("RTX 5080 FE", "Germany", nvidiaOffer)
+
+	sources := NewSources(slog.New(slog.NewTextHandler(io.Discard, nil)), fallback, s)
+
+	t.Run("page and fallback", func(t *testing.T) {
+		offers, err := sources.Check(context.Background(), "RTX 5090 FE", "Germany")
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+
+		if len(offers) != 2 || offers[0].Retailer != "Example Shop" || offers[1] != nvidiaOffer {
+			t.Errorf("Check() = %+v, want the offers of the page and the fallback", offers)
+		}
+	})
+
+	t.Run("fallback only", func(t *testing.T) {
+		offers, err := sources.Check(context.Background(), "RTX 5080 FE", "Germany")
+		if err != nil || len(offers) != 1 || offers[0] != nvidiaOffer {
+			t.Errorf("Check() = %+v, %v, want the fallback offer", offers, err)
+		}
+	})
+
+	t.Run("page only", func(t *testing.T) {
+		offers, err := sources.Check(context.Background(), "ASUS ROG Astral RTX 5090", "Germany")
+		if err != nil || len(offers) != 1 || offers[0].Retailer != "Example Shop" {
+			t.Errorf("Check() = %+v, %v, want the page offer", offers, err)
+		}
+
+		if calls := fallback.Calls("ASUS ROG Astral RTX 5090", "Germany"); calls != 0 {
+			t.Errorf("fallback checked %d times, want 0 for products it doesn't offer", calls)
+		}
+	})
+
+	t.Run("failing fallback", func(t *testing.T) {
+		fallback.SetError("RTX 5090 FE", "Germany", errors.New("API down"))
+		defer fallback.Set("RTX 5090 FE", "Germany", nvidiaOffer)
+
+		offers, err := sources.Check(context.Background(), "RTX 5090 FE", "Germany")
+		if err != nil || len(offers) != 1 || offers[0].Retailer != "Example Shop" {
+			t.Errorf("Check() = %+v, %v, want the page offer", offers, err)
+		}
+	})
+
+	t.Run("all failing", func(t *testing.T) {
+		if _, err := sources.Check(context.Background(), "ASUS ROG Astral RTX 5090", "France"); err == nil {
+			t.Error("Check() succeeded although all sources failed")
+		}
+	})
+
+	t.Run("not offered", func(t *testing.T) {
+		if sources.Offers("RTX 5070 FE", "Germany") {
+			t.Error("Offers() = true for a product of no source")
+		}
+
+		if _, err := sources.Check(context.Background(), "RTX 5070 FE", "Germany"); err == nil {
+			t.Error("Check() succeeded for a product of no source")
+		}
+	})
+}
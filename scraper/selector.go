@@ -0,0 +1,332 @@
+package scraper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+type (
+	// selector is a group of comma separated CSS selectors, matching elements
+	// matched by any of them.
+	selector []complexSelector
+
+	// complexSelector is a chain of compound selectors, e.g. "div.offer > span".
+	// The last compound matches the element, the others its ancestors.
+	complexSelector struct {
+		compounds []compound
+		// child[i] tells whether compounds[i] must be the parent of
+		// compounds[i+1] instead of any ancestor.
+		child []bool
+	}
+
+	// compound matches a single element, e.g. `a.buy[href^="https"]`.
+	compound struct {
+		tag   string
+		id    string
+		class []string
+		attrs []attrMatcher
+	}
+
+	attrMatcher struct {
+		name string
+		// op is one of "", "=", "~=", "^=", "$=" or "*=", empty only checks
+		// that the attribute is present.
+		op    string
+		value string
+	}
+)
+
+// compileSelector parses the subset of CSS selectors used by the scraper:
+// type, universal, id, class and attribute selectors combined with the
+// descendant and child combinators.
+func compileSelector(s string) (selector, error) {
+	var sel selector
+
+	for _, part := range strings.Split(s, ",") {
+		cs, err := compileComplex(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("parsing selector %q: %w", s, err)
+		}
+
+		sel = append(sel, cs)
+	}
+
+	return sel, nil
+}
+
+func compileComplex(s string) (complexSelector, error) {
+	var (
+		cs    complexSelector
+		child bool
+	)
+
+	if s == "" {
+		return cs, fmt.Errorf("empty selector")
+	}
+
+	for s != "" {
+		s = strings.TrimLeft(s, " \t\n")
+
+		if strings.HasPrefix(s, ">") {
+			if len(cs.compounds) == 0 || child {
+				return cs, fmt.Errorf("unexpected combinator")
+			}
+
+			child = true
+			s = s[1:]
+
+			continue
+		}
+
+		c, rest, err := compileCompound(s)
+		if err != nil {
+			return cs, err
+		}
+
+		if len(cs.compounds) > 0 {
+			cs.child = append(cs.child, child)
+		}
+
+		cs.compounds = append(cs.compounds, c)
+		child = false
+		s = rest
+	}
+
+	if child {
+		return cs, fmt.Errorf("missing selector after combinator")
+	}
+
+	return cs, nil
+}
+
+// compileCompound parses a compound selector from the start of s and returns
+// the rest.
+func compileCompound(s string) (compound, string, error) {
+	var c compound
+
+	name, s := readIdent(s)
+	c.tag = strings.ToLower(name)
+
+	if c.tag == "" && strings.HasPrefix(s, "*") {
+		c.tag, s = "*", s[1:]
+	}
+
+	for s != "" {
+		switch s[0] {
+		case '#':
+			c.id, s = readIdent(s[1:])
+			if c.id == "" {
+				return c, s, fmt.Errorf("missing id")
+			}
+		case '.':
+			var class string
+
+			class, s = readIdent(s[1:])
+			if class == "" {
+				return c, s, fmt.Errorf("missing class")
+			}
+
+			c.class = append(c.class, class)
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return c, s, fmt.Errorf("unterminated attribute selector")
+			}
+
+			attr, err := compileAttr(s[1:end])
+			if err != nil {
+				return c, s, err
+			}
+
+			c.attrs = append(c.attrs, attr)
+			s = s[end+1:]
+		case ' ', '\t', '\n', '>':
+			return c, s, nil
+		default:
+			return c, s, fmt.Errorf("unexpected %q", s[0])
+		}
+	}
+
+	if c.tag == "" && c.id == "" && len(c.class) == 0 && len(c.attrs) == 0 {
+		return c, s, fmt.Errorf("empty selector")
+	}
+
+	return c, s, nil
+}
+
+func compileAttr(s string) (attrMatcher, error) {
+	var a attrMatcher
+
+	i := strings.IndexByte(s, '=')
+	if i < 0 {
+		a.name = strings.ToLower(strings.TrimSpace(s))
+	} else {
+		a.name, a.op = s[:i], s[i:i+1]
+
+		if i > 0 && strings.ContainsRune("~^$*", rune(s[i-1])) {
+			a.name, a.op = s[:i-1], s[i-1:i+1]
+		}
+
+		a.name = strings.ToLower(strings.TrimSpace(a.name))
+		a.value = strings.TrimSpace(s[i+1:])
+
+		if len(a.value) >= 2 && (a.value[0] == '"' || a.value[0] == '\'') && a.value[len(a.value)-1] == a.value[0] {
+			a.value = a.value[1 : len(a.value)-1]
+		}
+	}
+
+	if a.name == "" {
+		return a, fmt.Errorf("missing attribute name")
+	}
+
+	return a, nil
+}
+
+func readIdent(s string) (string, string) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f)
+	})
+	if i < 0 {
+		return s, ""
+	}
+
+	return s[:i], s[i:]
+}
+
+// all returns the elements below the node matching the selector in document
+// order.
+func (sel selector) all(n *html.Node) []*html.Node {
+	var found []*html.Node
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && slices.ContainsFunc(sel, func(cs complexSelector) bool {
+			return cs.match(c)
+		}) {
+			found = append(found, c)
+		}
+
+		found = append(found, sel.all(c)...)
+	}
+
+	return found
+}
+
+func (cs complexSelector) match(n *html.Node) bool {
+	return cs.matchAt(n, len(cs.compounds)-1)
+}
+
+// matchAt reports whether the node matches the compounds up to i.
+func (cs complexSelector) matchAt(n *html.Node, i int) bool {
+	if !cs.compounds[i].match(n) {
+		return false
+	}
+
+	if i == 0 {
+		return true
+	}
+
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if cs.matchAt(p, i-1) {
+			return true
+		}
+
+		if cs.child[i-1] {
+			return false
+		}
+	}
+
+	return false
+}
+
+func (c compound) match(n *html.Node) bool {
+	if c.tag != "" && c.tag != "*" && c.tag != n.Data {
+		return false
+	}
+
+	if c.id != "" && attr(n, "id") != c.id {
+		return false
+	}
+
+	classes := strings.Fields(attr(n, "class"))
+
+	for _, class := range c.class {
+		if !slices.Contains(classes, class) {
+			return false
+		}
+	}
+
+	for _, a := range c.attrs {
+		if !a.match(n) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a attrMatcher) match(n *html.Node) bool {
+	var (
+		value string
+		found bool
+	)
+
+	for _, na := range n.Attr {
+		if na.Namespace == "" && na.Key == a.name {
+			value, found = na.Val, true
+			break
+		}
+	}
+
+	if !found {
+		return false
+	}
+
+	switch a.op {
+	case "=":
+		return value == a.value
+	case "~=":
+		return slices.Contains(strings.Fields(value), a.value)
+	case "^=":
+		return a.value != "" && strings.HasPrefix(value, a.value)
+	case "$=":
+		return a.value != "" && strings.HasSuffix(value, a.value)
+	case "*=":
+		return a.value != "" && strings.Contains(value, a.value)
+	default:
+		return true
+	}
+}
+
+// attr returns the value of the attribute of the node, empty if missing.
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// text returns the text content of the node.
+func text(n *html.Node) string {
+	var sb strings.Builder
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	walk(n)
+
+	return sb.String()
+}
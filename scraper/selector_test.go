@@ -0,0 +1,100 @@
+package scraper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestCompileSelector(t *testing.T) {
+	const doc = `<html><body>
+		<div id="product" class="product gpu">
+			<h1 id="title" class="title">RTX 5090</h1>
+			<div id="buy" class="buy-box">
+				<span id="price" class="price" data-currency="EUR" content="2399">2.399 €</span>
+				<a id="cart" class="button buy" href="https://shop.example/cart" rel="nofollow noopener">Buy</a>
+				<a id="wishlist" class="button" href="/wishlist">Save</a>
+			</div>
+		</div>
+		<aside id="aside">
+			<span id="other-price" class="price">999 €</span>
+		</aside>
+	</body></html>`
+
+	root, err := html.Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		selector string
+		// want are the ids of the matched elements.
+		want    []string
+		wantErr string
+	}{
+		{selector: "h1", want: []string{"title"}},
+		{selector: "H1", want: []string{"title"}},
+		{selector: "#price", want: []string{"price"}},
+		{selector: ".price", want: []string{"price", "other-price"}},
+		{selector: "span.price", want: []string{"price", "other-price"}},
+		{selector: ".button.buy", want: []string{"cart"}},
+		{selector: "div.gpu.product", want: []string{"product"}},
+		{selector: "section"},
+		{selector: "*#cart", want: []string{"cart"}},
+		{selector: "#product .price", want: []string{"price"}},
+		{selector: "#product > .price"},
+		{selector: "#product > div > .price", want: []string{"price"}},
+		{selector: "body  >  aside>span", want: []string{"other-price"}},
+		{selector: "#product span, aside span", want: []string{"price", "other-price"}},
+		{selector: "[data-currency]", want: []string{"price"}},
+		{selector: "[DATA-CURRENCY]", want: []string{"price"}},
+		{selector: `span[data-currency="EUR"]`, want: []string{"price"}},
+		{selector: "span[data-currency='USD']"},
+		{selector: "a[href^=https]", want: []string{"cart"}},
+		{selector: `a[href$="/wishlist"]`, want: []string{"wishlist"}},
+		{selector: `a[href*="shop.example"]`, want: []string{"cart"}},
+		{selector: "a[rel~=noopener]", want: []string{"cart"}},
+		{selector: "a[rel~=noop]"},
+		{selector: `a[href^=""]`},
+		{selector: "", wantErr: "empty selector"},
+		{selector: "h1,", wantErr: "empty selector"},
+		{selector: "> h1", wantErr: "unexpected combinator"},
+		{selector: "div > > h1", wantErr: "unexpected combinator"},
+		{selector: "div >", wantErr: "missing selector after combinator"},
+		{selector: "#", wantErr: "missing id"},
+		{selector: "div.", wantErr: "missing class"},
+		{selector: "a[href", wantErr: "unterminated attribute selector"},
+		{selector: "a[=x]", wantErr: "missing attribute name"},
+		{selector: "a:hover", wantErr: `unexpected ':'`},
+		{selector: "div + h1", wantErr: `unexpected '+'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := compileSelector(tt.selector)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("compileSelector() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("compileSelector() error = %v", err)
+			}
+
+			var got []string
+
+			for _, n := range sel.all(root) {
+				got = append(got, attr(n, "id"))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("all() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
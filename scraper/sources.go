@@ -0,0 +1,118 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/dyptan-io/rtx-sniper-bot/monitor"
+	"github.com/dyptan-io/rtx-sniper-bot/nvidia"
+)
+
+// Sources implements monitor.StockSource. It checks the retailers with a page
+// of the product and the fallback, e.g. the NVIDIA API, if it offers the
+// product too.
+type Sources struct {
+	fallback monitor.StockSource
+	scrapers []*Scraper
+	log      *slog.Logger
+}
+
+func NewSources(log *slog.Logger, fallback monitor.StockSource, scrapers ...*Scraper) *Sources {
+	return &Sources{
+		fallback: fallback,
+		scrapers: scrapers,
+		log:      log,
+	}
+}
+
+// Check returns the offers of all retailers with a page of the product and of
+// the fallback. It only fails if all of them fail, failures of single sources
+// are logged.
+func (s *Sources) Check(ctx context.Context, product, country string) ([]monitor.Offer, error) {
+	var (
+		stocks  []monitor.Offer
+		errs    []error
+		checked int
+	)
+
+	for _, sc := range s.scrapers {
+		if !sc.Offers(product, country) {
+			continue
+		}
+
+		checked++
+
+		offers, err := sc.Check(ctx, product, country)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sc.Name(), err))
+			continue
+		}
+
+		stocks = append(stocks, offers...)
+	}
+
+	// Fallbacks without a catalog are only checked for products without
+	// pages, they can't tell whether they offer them too.
+	if c, ok := s.fallback.(monitor.Catalog); ok && c.Offers(product, country) || !ok && checked == 0 {
+		checked++
+
+		offers, err := s.fallback.Check(ctx, product, country)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		stocks = append(stocks, offers...)
+	}
+
+	if checked == 0 {
+		return nil, fmt.Errorf("no source offers %s in %s", product, country)
+	}
+
+	if len(errs) == checked {
+		return nil, errors.Join(errs...)
+	}
+
+	for _, err := range errs {
+		s.log.Error("Failed to check stock source.", "product", product, "country", country, "error", err)
+	}
+
+	return stocks, nil
+}
+
+// Offers implements monitor.Catalog. The product is offered if a retailer
+// has a page of it or the fallback offers it, like the monitor assumes for
+// fallbacks not implementing monitor.Catalog.
+func (s *Sources) Offers(product, country string) bool {
+	if slices.ContainsFunc(s.scrapers, func(sc *Scraper) bool {
+		return sc.Offers(product, country)
+	}) {
+		return true
+	}
+
+	c, ok := s.fallback.(monitor.Catalog)
+
+	return !ok || c.Offers(product, country)
+}
+
+// Products returns the products and countries of the retailer pages.
+func (s *Sources) Products() ([]nvidia.Product, []nvidia.Country) {
+	var (
+		products  []nvidia.Product
+		countries []nvidia.Country
+	)
+
+	for _, sc := range s.scrapers {
+		for key := range sc.pages {
+			products = append(products, nvidia.Product(key.product))
+			countries = append(countries, nvidia.Country(key.country))
+		}
+	}
+
+	slices.Sort(products)
+	slices.Sort(countries)
+
+	return slices.Compact(products), slices.Compact(countries)
+}
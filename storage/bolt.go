@@ -0,0 +1,496 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bolt is a Store kept in a bucket of a bolt database. Items are written
+// individually and indexed, so it suits a large number of items.
+type Bolt[T any] struct {
+	db      *bolt.DB
+	bucket  []byte
+	index   []byte
+	expires []byte
+	opts    options
+	// writeMu orders the events like the transactions.
+	writeMu  sync.Mutex
+	watchers watchers[T]
+}
+
+// metaBucket holds the versions of the stores in a database.
+var metaBucket = []byte("meta")
+
+// OpenBolt opens the store in the named bucket, creating it if necessary, and
+// migrates its items to the current version. The database is shared between
+// stores and closed by the caller.
+func OpenBolt[T any](db *bolt.DB, name string, opts ...Option) (*Bolt[T], error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := Bolt[T]{
+		db:      db,
+		bucket:  []byte(name),
+		index:   []byte(name + ".index"),
+		expires: []byte(name + ".expires"),
+		opts:    o,
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists(s.expires); err != nil {
+			return err
+		}
+
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+
+		var version int
+
+		if v := meta.Get(s.bucket); v != nil {
+			if version, err = strconv.Atoi(string(v)); err != nil {
+				return fmt.Errorf("parsing version of %s: %w", name, err)
+			}
+		}
+
+		if err := o.check(version); err != nil {
+			return err
+		}
+
+		if o.crypter == nil {
+			if _, v := b.Cursor().First(); sealed(v) {
+				return fmt.Errorf("%s is encrypted, but no encryption key is set", name)
+			}
+		}
+
+		// Encrypted items are checked for old keys, see WithEncryption.
+		if version != o.version() || o.crypter != nil {
+			if err := s.upgrade(b, version); err != nil {
+				return err
+			}
+		}
+
+		if version != o.version() {
+			if err := meta.Put(s.bucket, []byte(strconv.Itoa(o.version()))); err != nil {
+				return err
+			}
+		}
+
+		if tx.Bucket(s.index) != nil {
+			return nil
+		}
+
+		idx, err := tx.CreateBucket(s.index)
+		if err != nil {
+			return err
+		}
+
+		// Index the items stored before the index existed.
+		return b.ForEach(func(k, v []byte) error {
+			item, err := s.decode(v)
+			if err != nil {
+				return err
+			}
+
+			return addIndex(idx, k, item)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// upgrade migrates the items from the version and encrypts them with the
+// current key. The index is dropped to rebuild it from migrated items.
+func (s *Bolt[T]) upgrade(b *bolt.Bucket, version int) error {
+	var (
+		upgraded = make(map[string][]byte)
+		removed  [][]byte
+	)
+
+	err := b.ForEach(func(k, v []byte) error {
+		item, reseal, err := s.opts.decrypt(bytes.Clone(v))
+		if err != nil {
+			return fmt.Errorf("decrypting %q: %w", k, err)
+		}
+
+		if version == s.opts.version() && !reseal {
+			return nil
+		}
+
+		items, err := s.opts.migrate(version, string(k), item)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := items[string(k)]; !ok {
+			removed = append(removed, bytes.Clone(k))
+		}
+
+		for key, item := range items {
+			if upgraded[key], err = s.opts.encrypt(item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range removed {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+
+		if err := b.Tx().Bucket(s.expires).Delete(k); err != nil {
+			return err
+		}
+	}
+
+	for k, v := range upgraded {
+		if err := b.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+
+	if version == s.opts.version() {
+		return nil
+	}
+
+	if err := b.Tx().DeleteBucket(s.index); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// encode marshals and encrypts the item.
+func (s *Bolt[T]) encode(item T) ([]byte, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.opts.encrypt(data)
+}
+
+// decode decrypts and unmarshals the item.
+func (s *Bolt[T]) decode(data []byte) (T, error) {
+	var item T
+
+	data, _, err := s.opts.decrypt(data)
+	if err != nil {
+		return item, err
+	}
+
+	err = json.Unmarshal(data, &item)
+
+	return item, err
+}
+
+func (s *Bolt[T]) Add(key string, item T) error {
+	data, err := s.encode(item)
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	var (
+		old T
+		ok  bool
+	)
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b, idx := tx.Bucket(s.bucket), tx.Bucket(s.index)
+
+		if old, ok, err = s.removeIndex(b, idx, []byte(key)); err != nil {
+			return err
+		}
+
+		if err := b.Put([]byte(key), data); err != nil {
+			return err
+		}
+
+		return addIndex(idx, []byte(key), item)
+	})
+	if err != nil {
+		return err
+	}
+
+	e := Event[T]{Type: EventAdd, Key: key, Item: item, Old: old}
+	if ok {
+		e.Type = EventUpdate
+	}
+
+	s.watchers.emit(e)
+
+	return nil
+}
+
+func (s *Bolt[T]) Remove(key string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	var (
+		old T
+		ok  bool
+	)
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var err error
+
+		b, idx := tx.Bucket(s.bucket), tx.Bucket(s.index)
+
+		if old, ok, err = s.removeIndex(b, idx, []byte(key)); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(s.expires).Delete([]byte(key)); err != nil {
+			return err
+		}
+
+		return b.Delete([]byte(key))
+	})
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		s.watchers.emit(Event[T]{Type: EventRemove, Key: key, Old: old})
+	}
+
+	return nil
+}
+
+func (s *Bolt[T]) Get(key string) (T, bool) {
+	var (
+		item T
+		ok   bool
+	)
+
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(s.bucket).Get([]byte(key)); v != nil {
+			var err error
+
+			item, err = s.decode(v)
+			ok = err == nil
+		}
+
+		return nil
+	})
+
+	return item, ok
+}
+
+// All returns the items ordered by key. The items are read up front, so the
+// store can be modified while iterating.
+func (s *Bolt[T]) All() iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		var entries []entry
+
+		_ = s.db.View(func(tx *bolt.Tx) error {
+			return tx.Bucket(s.bucket).ForEach(func(k, v []byte) error {
+				entries = append(entries, entry{key: string(k), value: bytes.Clone(v)})
+				return nil
+			})
+		})
+
+		s.yield(entries, yield)
+	}
+}
+
+// Find looks up the index and returns the matching items ordered by key.
+func (s *Bolt[T]) Find(index, value string) iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		var entries []entry
+
+		_ = s.db.View(func(tx *bolt.Tx) error {
+			b, c := tx.Bucket(s.bucket), tx.Bucket(s.index).Cursor()
+			prefix := indexKey(index, value, nil)
+
+			for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+				key := k[len(prefix):]
+
+				if v := b.Get(key); v != nil {
+					entries = append(entries, entry{key: string(key), value: bytes.Clone(v)})
+				}
+			}
+
+			return nil
+		})
+
+		s.yield(entries, yield)
+	}
+}
+
+// Watch returns the changes of the items until the context is done.
+func (s *Bolt[T]) Watch(ctx context.Context) <-chan Event[T] {
+	return s.watchers.watch(ctx)
+}
+
+func (s *Bolt[T]) SetExpiry(key string, at time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(s.bucket).Get([]byte(key)) == nil {
+			return nil
+		}
+
+		exp := tx.Bucket(s.expires)
+
+		if at.IsZero() {
+			return exp.Delete([]byte(key))
+		}
+
+		v, err := at.MarshalText()
+		if err != nil {
+			return err
+		}
+
+		return exp.Put([]byte(key), v)
+	})
+}
+
+func (s *Bolt[T]) Expiry(key string) (time.Time, bool) {
+	var (
+		at time.Time
+		ok bool
+	)
+
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(s.expires).Get([]byte(key)); v != nil {
+			ok = at.UnmarshalText(v) == nil
+		}
+
+		return nil
+	})
+
+	return at, ok
+}
+
+func (s *Bolt[T]) Expiring(before time.Time) iter.Seq2[string, time.Time] {
+	return func(yield func(string, time.Time) bool) {
+		expiring := make(map[string]time.Time)
+
+		_ = s.db.View(func(tx *bolt.Tx) error {
+			return tx.Bucket(s.expires).ForEach(func(k, v []byte) error {
+				var at time.Time
+
+				if at.UnmarshalText(v) == nil && at.Before(before) {
+					expiring[string(k)] = at
+				}
+
+				return nil
+			})
+		})
+
+		for k, at := range expiring {
+			if !yield(k, at) {
+				return
+			}
+		}
+	}
+}
+
+// Close is a no-op, the database is closed by its owner.
+func (s *Bolt[T]) Close() error {
+	return nil
+}
+
+type entry struct {
+	key   string
+	value []byte
+}
+
+func (s *Bolt[T]) yield(entries []entry, yield func(string, T) bool) {
+	for _, e := range entries {
+		item, err := s.decode(e.value)
+		if err != nil {
+			continue
+		}
+
+		if !yield(e.key, item) {
+			return
+		}
+	}
+}
+
+// removeIndex removes the index entries of the currently stored item and
+// returns the item.
+func (s *Bolt[T]) removeIndex(b, idx *bolt.Bucket, key []byte) (T, bool, error) {
+	v := b.Get(key)
+	if v == nil {
+		var item T
+		return item, false, nil
+	}
+
+	item, err := s.decode(v)
+	if err != nil {
+		return item, true, nil
+	}
+
+	i, ok := any(item).(Indexer)
+	if !ok {
+		return item, true, nil
+	}
+
+	for index, values := range i.Indexes() {
+		for _, value := range values {
+			if err := idx.Delete(indexKey(index, value, key)); err != nil {
+				return item, true, err
+			}
+		}
+	}
+
+	return item, true, nil
+}
+
+func addIndex(idx *bolt.Bucket, key []byte, item any) error {
+	i, ok := item.(Indexer)
+	if !ok {
+		return nil
+	}
+
+	for index, values := range i.Indexes() {
+		for _, value := range values {
+			if err := idx.Put(indexKey(index, value, key), []byte{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// indexKey joins the index name, the value and the item key with zero bytes,
+// so a cursor finds all keys of a value by prefix.
+func indexKey(index, value string, key []byte) []byte {
+	k := make([]byte, 0, len(index)+len(value)+len(key)+2)
+	k = append(k, index...)
+	k = append(k, 0)
+	k = append(k, value...)
+	k = append(k, 0)
+
+	return append(k, key...)
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// sealedMagic starts encrypted data, which JSON never does. It's followed by
+// the key ID, the nonce and the AES-GCM sealed data. The magic and the key ID
+// form the header, which is sealed as additional data.
+var sealedMagic = []byte("\x00SNP\x01")
+
+const keyIDSize = 8
+
+type (
+	// crypter encrypts with the first key and decrypts with any key, so keys
+	// can be rotated by adding a new key in front of the old ones.
+	crypter struct {
+		keys []aeadKey
+	}
+
+	aeadKey struct {
+		id   []byte
+		aead cipher.AEAD
+	}
+)
+
+// WithEncryption encrypts the stored items with AES-GCM using the first key.
+// Items encrypted with the other keys are encrypted with the first key when
+// the store is opened, which rotates the keys. Keys are 16, 24 or 32 bytes
+// long for AES-128, AES-192 or AES-256. Unencrypted stores are encrypted.
+// Bolt encrypts the values only, keys, index entries and expiries are stored
+// in plaintext to look them up.
+func WithEncryption(keys ...[]byte) Option {
+	return func(o *options) {
+		o.keys = keys
+	}
+}
+
+func newCrypter(keys [][]byte) (*crypter, error) {
+	var c crypter
+
+	for _, k := range keys {
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key: %w", err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		// Identify keys by hash, so the data names the key it needs.
+		sum := sha256.Sum256(k)
+
+		c.keys = append(c.keys, aeadKey{
+			id:   sum[:keyIDSize],
+			aead: aead,
+		})
+	}
+
+	return &c, nil
+}
+
+func (c *crypter) encrypt(data []byte) ([]byte, error) {
+	k := c.keys[0]
+	nonce := make([]byte, k.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := append(bytes.Clone(sealedMagic), k.id...)
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(data)+k.aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+
+	// The header is authenticated, so the key ID can't be swapped.
+	return k.aead.Seal(out, nonce, data, header), nil
+}
+
+// decrypt returns the plain data and whether it was encrypted with the
+// current key. Unencrypted data is returned as is.
+func (c *crypter) decrypt(data []byte) ([]byte, bool, error) {
+	if !sealed(data) {
+		return data, false, nil
+	}
+
+	if len(data) < len(sealedMagic)+keyIDSize {
+		return nil, false, errors.New("truncated encrypted data")
+	}
+
+	header, data := data[:len(sealedMagic)+keyIDSize], data[len(sealedMagic)+keyIDSize:]
+	id := header[len(sealedMagic):]
+
+	for i, k := range c.keys {
+		if !bytes.Equal(k.id, id) {
+			continue
+		}
+
+		if len(data) < k.aead.NonceSize() {
+			return nil, false, errors.New("truncated encrypted data")
+		}
+
+		nonce, data := data[:k.aead.NonceSize()], data[k.aead.NonceSize():]
+
+		plain, err := k.aead.Open(nil, nonce, data, header)
+		if err != nil {
+			return nil, false, fmt.Errorf("decrypting: %w", err)
+		}
+
+		return plain, i == 0, nil
+	}
+
+	return nil, false, errors.New("data is encrypted with an unknown key")
+}
+
+func sealed(data []byte) bool {
+	return bytes.HasPrefix(data, sealedMagic)
+}
+
+// encrypt is a no-op without encryption keys.
+func (o options) encrypt(data []byte) ([]byte, error) {
+	if o.crypter == nil {
+		return data, nil
+	}
+
+	return o.crypter.encrypt(data)
+}
+
+// decrypt returns the plain data and whether it must be encrypted again,
+// because it's not encrypted with the current key.
+func (o options) decrypt(data []byte) ([]byte, bool, error) {
+	if o.crypter == nil {
+		if sealed(data) {
+			return nil, false, errors.New("data is encrypted, but no encryption key is set")
+		}
+
+		return data, false, nil
+	}
+
+	plain, current, err := o.crypter.decrypt(data)
+
+	return plain, !current, err
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	oldKey = bytes.Repeat([]byte{1}, 32)
+	newKey = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestCrypter(t *testing.T) {
+	plain := []byte(`{"version":1,"items":{}}`)
+
+	old, err := newCrypter([][]byte{oldKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealedData, err := old.encrypt(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(sealedData, plain) {
+		t.Fatal("encrypted data contains the plain data")
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		got, current, err := old.decrypt(sealedData)
+		if err != nil || !bytes.Equal(got, plain) || !current {
+			t.Errorf("decrypt() = %q, %t, %v, want the plain data with the current key", got, current, err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		c, err := newCrypter([][]byte{newKey})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := c.decrypt(sealedData); err == nil {
+			t.Error("decrypt() succeeded with the wrong key")
+		}
+	})
+
+	t.Run("rotated", func(t *testing.T) {
+		c, err := newCrypter([][]byte{newKey, oldKey})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, current, err := c.decrypt(sealedData)
+		if err != nil || !bytes.Equal(got, plain) || current {
+			t.Errorf("decrypt() = %q, %t, %v, want the plain data with an old key", got, current, err)
+		}
+	})
+
+	header := len(sealedMagic) + keyIDSize
+
+	for _, tt := range []struct {
+		name   string
+		tamper func(data []byte) []byte
+	}{
+		{"magic", func(data []byte) []byte { data[len(sealedMagic)-1] ^= 1; return data }},
+		{"key ID", func(data []byte) []byte { data[header-1] ^= 1; return data }},
+		{"nonce", func(data []byte) []byte { data[header] ^= 1; return data }},
+		{"ciphertext", func(data []byte) []byte { data[len(data)-20] ^= 1; return data }},
+		{"tag", func(data []byte) []byte { data[len(data)-1] ^= 1; return data }},
+		{"truncated", func(data []byte) []byte { return data[:header-1] }},
+	} {
+		t.Run("tampered "+tt.name, func(t *testing.T) {
+			data := tt.tamper(bytes.Clone(sealedData))
+
+			if got, _, err := old.decrypt(data); err == nil && bytes.Equal(got, plain) {
+				t.Error("decrypt() accepted tampered data")
+			}
+		})
+	}
+}
+
+func TestCrypterInvalidKey(t *testing.T) {
+	if _, err := newCrypter([][]byte{[]byte("short")}); err == nil {
+		t.Error("newCrypter() accepted a 5 byte key")
+	}
+}
+
+// reopeners return constructors of every Store implementation that open the
+// same file again on every call.
+func reopeners(t *testing.T) map[string]func(opts ...Option) (Store[tagged], func(), error) {
+	dir := t.TempDir()
+
+	return map[string]func(opts ...Option) (Store[tagged], func(), error){
+		"json": func(opts ...Option) (Store[tagged], func(), error) {
+			s, err := Open[tagged](filepath.Join(dir, "store.json"), opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return s, func() { s.Close() }, nil
+		},
+		"bolt": func(opts ...Option) (Store[tagged], func(), error) {
+			db, err := bolt.Open(filepath.Join(dir, "store.db"), 0600, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			s, err := OpenBolt[tagged](db, "items", opts...)
+			if err != nil {
+				db.Close()
+				return nil, nil, err
+			}
+
+			return s, func() { db.Close() }, nil
+		},
+	}
+}
+
+func TestStoreEncryption(t *testing.T) {
+	for backend, open := range reopeners(t) {
+		t.Run(backend, func(t *testing.T) {
+			s, closeStore, err := open(WithEncryption(oldKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			add(t, s, "a", "x")
+			closeStore()
+
+			for name, opts := range map[string][]Option{
+				"the wrong key": {WithEncryption(newKey)},
+				"no key":        nil,
+			} {
+				if _, closeStore, err := open(opts...); err == nil {
+					closeStore()
+					t.Fatalf("opened the encrypted store with %s", name)
+				}
+			}
+
+			// Opening with the new key in front rotates the key.
+			s, closeStore, err = open(WithEncryption(newKey, oldKey))
+			if err != nil {
+				t.Fatalf("opening with the rotated keys: %v", err)
+			}
+
+			if item, ok := s.Get("a"); !ok || !slices.Equal(item.Tags, []string{"x"}) {
+				t.Errorf("Get() = %+v, %t after rotating", item, ok)
+			}
+
+			closeStore()
+
+			s, closeStore, err = open(WithEncryption(newKey))
+			if err != nil {
+				t.Fatalf("opening with the new key only: %v", err)
+			}
+
+			defer closeStore()
+
+			if item, ok := s.Get("a"); !ok || !slices.Equal(item.Tags, []string{"x"}) {
+				t.Errorf("Get() = %+v, %t with the new key only", item, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+)
+
+type (
+	// Migration upgrades a stored item from the previous version.
+	Migration func(key string, item json.RawMessage) (json.RawMessage, error)
+
+	// KeyMigration upgrades a stored item from the previous version to items
+	// by key, e.g. to store the elements of a list under keys of their own.
+	// The item is removed if its key isn't returned.
+	KeyMigration func(key string, item json.RawMessage) (map[string]json.RawMessage, error)
+
+	// envelope is the versioned format of the JSON file.
+	envelope struct {
+		Version *int                       `json:"version"`
+		Items   map[string]json.RawMessage `json:"items"`
+		Expires map[string]time.Time       `json:"expires,omitempty"`
+	}
+)
+
+// WithMigrations sets the migrations of the items. The version of the stored
+// items is the number of migrations, items stored with an older version are
+// upgraded on load by the remaining migrations in order.
+func WithMigrations(migrations ...Migration) Option {
+	keyMigrations := make([]KeyMigration, 0, len(migrations))
+
+	for _, m := range migrations {
+		keyMigrations = append(keyMigrations, func(key string, item json.RawMessage) (map[string]json.RawMessage, error) {
+			item, err := m(key, item)
+			if err != nil {
+				return nil, err
+			}
+
+			return map[string]json.RawMessage{key: item}, nil
+		})
+	}
+
+	return WithKeyMigrations(keyMigrations...)
+}
+
+// WithKeyMigrations sets migrations which may change the keys of the items,
+// see WithMigrations.
+func WithKeyMigrations(migrations ...KeyMigration) Option {
+	return func(o *options) {
+		o.migrations = migrations
+	}
+}
+
+// Version returns the current version of items stored with the options.
+func Version(opts ...Option) int {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o.version()
+}
+
+// Migrate upgrades an item stored with the version to the current version of
+// the options, e.g. to import items exported by an older version. It returns
+// the upgraded items by key.
+func Migrate(version int, key string, item json.RawMessage, opts ...Option) (map[string]json.RawMessage, error) {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o.migrate(version, key, item)
+}
+
+func (o options) version() int {
+	return len(o.migrations)
+}
+
+// check fails for items stored by a newer version of the application.
+func (o options) check(version int) error {
+	if version > o.version() {
+		return fmt.Errorf("stored version %d is newer than the supported version %d", version, o.version())
+	}
+
+	return nil
+}
+
+// migrate upgrades the item from the version to the current one, returning
+// the upgraded items by key.
+func (o options) migrate(version int, key string, item json.RawMessage) (map[string]json.RawMessage, error) {
+	if err := o.check(version); err != nil {
+		return nil, err
+	}
+
+	items := map[string]json.RawMessage{key: item}
+
+	for v, m := range o.migrations[version:] {
+		upgraded := make(map[string]json.RawMessage, len(items))
+
+		for key, item := range items {
+			migrated, err := m(key, item)
+			if err != nil {
+				return nil, fmt.Errorf("migrating %q to version %d: %w", key, version+v+1, err)
+			}
+
+			maps.Copy(upgraded, migrated)
+		}
+
+		items = upgraded
+	}
+
+	return items, nil
+}
+
+// decodeEnvelope reads the file data. Files written before versioning hold
+// the items only and have version 0.
+func decodeEnvelope(data []byte) (envelope, error) {
+	var env envelope
+
+	if err := json.Unmarshal(data, &env); err == nil && env.Version != nil && env.Items != nil {
+		return env, nil
+	}
+
+	env = envelope{Version: new(int)}
+
+	if err := json.Unmarshal(data, &env.Items); err != nil {
+		return envelope{}, err
+	}
+
+	return env, nil
+}
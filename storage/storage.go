@@ -1,21 +1,133 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"iter"
+	"maps"
 	"os"
+	"slices"
 	"sync"
+	"time"
 )
 
-type Storage[T any] struct {
-	file    *os.File
-	items   map[string]T
-	itemsMu sync.RWMutex
+type (
+	// Store is a persistent collection of items by key.
+	Store[T any] interface {
+		Add(key string, item T) error
+		Remove(key string) error
+		Get(key string) (T, bool)
+		All() iter.Seq2[string, T]
+		// Find returns the items whose index contains the value. Only items
+		// implementing Indexer are indexed.
+		Find(index, value string) iter.Seq2[string, T]
+		// Watch returns the changes of the items until the context is done.
+		Watch(ctx context.Context) <-chan Event[T]
+		// SetExpiry sets the time the item expires at, the zero time keeps
+		// it forever. Expired items are removed by the owner of the store.
+		SetExpiry(key string, at time.Time) error
+		// Expiry returns the time the item expires at.
+		Expiry(key string) (time.Time, bool)
+		// Expiring returns the keys of items expiring before the time.
+		Expiring(before time.Time) iter.Seq2[string, time.Time]
+		Close() error
+	}
+
+	// Batcher is implemented by stores that write the changes made by fn at
+	// once, instead of once per change.
+	Batcher interface {
+		Batch(fn func() error) error
+	}
+
+	// Indexer is implemented by items that can be queried with Store.Find.
+	Indexer interface {
+		// Indexes returns the values of the item by index name.
+		Indexes() map[string][]string
+	}
+
+	Option func(*options)
+
+	options struct {
+		migrations []KeyMigration
+		keys       [][]byte
+		crypter    *crypter
+	}
+
+	// Storage is a Store kept in memory and written to a JSON file on every
+	// change. It suits a small number of items.
+	Storage[T any] struct {
+		file     *os.File
+		ownsFile bool
+		opts     options
+		items    map[string]T
+		expires  map[string]time.Time
+		itemsMu  sync.RWMutex
+		// batching defers saving the file to the end of Batch.
+		batching bool
+		watchers watchers[T]
+	}
+)
+
+func newOptions(opts []Option) (options, error) {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if len(o.keys) > 0 {
+		var err error
+
+		if o.crypter, err = newCrypter(o.keys); err != nil {
+			return options{}, err
+		}
+	}
+
+	return o, nil
+}
+
+// New returns a Storage kept in memory only.
+func New[T any]() *Storage[T] {
+	return &Storage[T]{
+		items:   make(map[string]T),
+		expires: make(map[string]time.Time),
+	}
+}
+
+// Open opens or creates the JSON file and loads its items. Close closes the
+// file.
+func Open[T any](name string, opts ...Option) (*Storage[T], error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := Load[T](f, opts...)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	s.ownsFile = true
+
+	return s, nil
 }
 
-func Load[T any](f *os.File) (*Storage[T], error) {
-	var items map[string]T
+// Load reads the items from the file, migrating them to the current version.
+func Load[T any](f *os.File, opts ...Option) (*Storage[T], error) {
+	var (
+		items   = make(map[string]T)
+		expires = make(map[string]time.Time)
+		reseal  bool
+	)
+
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	data, err := io.ReadAll(f)
 	if err != nil {
@@ -23,25 +135,75 @@ func Load[T any](f *os.File) (*Storage[T], error) {
 	}
 
 	if len(data) != 0 {
-		if err := json.Unmarshal(data, &items); err != nil {
+		if data, reseal, err = o.decrypt(data); err != nil {
 			return nil, err
 		}
-	} else {
-		items = make(map[string]T)
+
+		env, err := decodeEnvelope(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := o.check(*env.Version); err != nil {
+			return nil, err
+		}
+
+		maps.Copy(expires, env.Expires)
+
+		for key, v := range env.Items {
+			migrated, err := o.migrate(*env.Version, key, v)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := migrated[key]; !ok {
+				delete(expires, key)
+			}
+
+			for key, v := range migrated {
+				var item T
+
+				if err := json.Unmarshal(v, &item); err != nil {
+					return nil, fmt.Errorf("decoding %q: %w", key, err)
+				}
+
+				items[key] = item
+			}
+		}
 	}
 
-	return &Storage[T]{
-		file:  f,
-		items: items,
-	}, nil
+	s := Storage[T]{
+		file:    f,
+		opts:    o,
+		items:   items,
+		expires: expires,
+	}
+
+	// Encrypt with the current key right away to complete key rotations.
+	if reseal {
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &s, nil
 }
 
 func (s *Storage[T]) Add(key string, item T) error {
 	s.itemsMu.Lock()
 	defer s.itemsMu.Unlock()
 
+	old, ok := s.items[key]
 	s.items[key] = item
 
+	// Readers see the change even if it couldn't be saved.
+	e := Event[T]{Type: EventAdd, Key: key, Item: item, Old: old}
+	if ok {
+		e.Type = EventUpdate
+	}
+
+	s.watchers.emit(e)
+
 	return s.save()
 }
 
@@ -49,11 +211,32 @@ func (s *Storage[T]) Remove(key string) error {
 	s.itemsMu.Lock()
 	defer s.itemsMu.Unlock()
 
+	old, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+
 	delete(s.items, key)
+	delete(s.expires, key)
+	s.watchers.emit(Event[T]{Type: EventRemove, Key: key, Old: old})
 
 	return s.save()
 }
 
+// Watch returns the changes of the items until the context is done.
+func (s *Storage[T]) Watch(ctx context.Context) <-chan Event[T] {
+	return s.watchers.watch(ctx)
+}
+
+func (s *Storage[T]) Get(key string) (T, bool) {
+	s.itemsMu.RLock()
+	defer s.itemsMu.RUnlock()
+
+	item, ok := s.items[key]
+
+	return item, ok
+}
+
 func (s *Storage[T]) All() iter.Seq2[string, T] {
 	return func(yield func(string, T) bool) {
 		s.itemsMu.RLock()
@@ -67,6 +250,70 @@ func (s *Storage[T]) All() iter.Seq2[string, T] {
 	}
 }
 
+// Find scans all items for the index value.
+func (s *Storage[T]) Find(index, value string) iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		for k, v := range s.All() {
+			if !indexed(v, index, value) {
+				continue
+			}
+
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+func (s *Storage[T]) SetExpiry(key string, at time.Time) error {
+	s.itemsMu.Lock()
+	defer s.itemsMu.Unlock()
+
+	if _, ok := s.items[key]; !ok {
+		return nil
+	}
+
+	if at.IsZero() {
+		delete(s.expires, key)
+	} else {
+		s.expires[key] = at
+	}
+
+	return s.save()
+}
+
+func (s *Storage[T]) Expiry(key string) (time.Time, bool) {
+	s.itemsMu.RLock()
+	defer s.itemsMu.RUnlock()
+
+	at, ok := s.expires[key]
+
+	return at, ok
+}
+
+func (s *Storage[T]) Expiring(before time.Time) iter.Seq2[string, time.Time] {
+	return func(yield func(string, time.Time) bool) {
+		expiring := make(map[string]time.Time)
+
+		// Collect first, so the items can be removed while iterating.
+		s.itemsMu.RLock()
+
+		for k, at := range s.expires {
+			if at.Before(before) {
+				expiring[k] = at
+			}
+		}
+
+		s.itemsMu.RUnlock()
+
+		for k, at := range expiring {
+			if !yield(k, at) {
+				return
+			}
+		}
+	}
+}
+
 func (s *Storage[T]) Close() error {
 	s.itemsMu.Lock()
 
@@ -75,15 +322,52 @@ func (s *Storage[T]) Close() error {
 		s.itemsMu.Unlock()
 	}()
 
-	return s.save()
+	if err := s.save(); err != nil {
+		return err
+	}
+
+	if s.ownsFile {
+		return s.file.Close()
+	}
+
+	return nil
+}
+
+// Batch saves the file once after fn, e.g. to import many items. Changes of
+// fn are kept even if it fails.
+func (s *Storage[T]) Batch(fn func() error) error {
+	s.itemsMu.Lock()
+	s.batching = true
+	s.itemsMu.Unlock()
+
+	err := fn()
+
+	s.itemsMu.Lock()
+	defer s.itemsMu.Unlock()
+
+	s.batching = false
+
+	return errors.Join(err, s.save())
 }
 
 func (s *Storage[T]) save() error {
-	data, err := json.MarshalIndent(s.items, "", "  ")
+	if s.file == nil || s.batching {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Version int                  `json:"version"`
+		Items   map[string]T         `json:"items"`
+		Expires map[string]time.Time `json:"expires,omitempty"`
+	}{s.opts.version(), s.items, s.expires}, "", "  ")
 	if err != nil {
 		return err
 	}
 
+	if data, err = s.opts.encrypt(data); err != nil {
+		return err
+	}
+
 	if err := s.file.Truncate(0); err != nil {
 		return err
 	}
@@ -98,3 +382,12 @@ func (s *Storage[T]) save() error {
 
 	return nil
 }
+
+func indexed(item any, index, value string) bool {
+	i, ok := item.(Indexer)
+	if !ok {
+		return false
+	}
+
+	return slices.Contains(i.Indexes()[index], value)
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// tagged is an item indexed by its tags.
+type tagged struct {
+	Tags []string `json:"tags"`
+}
+
+func (t tagged) Indexes() map[string][]string {
+	return map[string][]string{"tag": t.Tags}
+}
+
+// backends returns constructors of every Store implementation, each store is
+// backed by a file in a temporary directory.
+func backends() map[string]func(t *testing.T) Store[tagged] {
+	return map[string]func(t *testing.T) Store[tagged]{
+		"json": func(t *testing.T) Store[tagged] {
+			t.Helper()
+
+			s, err := Open[tagged](filepath.Join(t.TempDir(), "store.json"))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			t.Cleanup(func() { s.Close() })
+
+			return s
+		},
+		"bolt": func(t *testing.T) Store[tagged] {
+			t.Helper()
+
+			db, err := bolt.Open(filepath.Join(t.TempDir(), "store.db"), 0600, nil)
+			if err != nil {
+				t.Fatalf("opening database: %v", err)
+			}
+
+			t.Cleanup(func() { db.Close() })
+
+			s, err := OpenBolt[tagged](db, "items")
+			if err != nil {
+				t.Fatalf("OpenBolt() error = %v", err)
+			}
+
+			return s
+		},
+	}
+}
+
+func TestStoreFind(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, s Store[tagged])
+		value  string
+		want   []string
+	}{
+		{
+			name: "indexed",
+			change: func(t *testing.T, s Store[tagged]) {
+				add(t, s, "a", "x")
+				add(t, s, "b", "x", "y")
+				add(t, s, "c", "y")
+			},
+			value: "x",
+			want:  []string{"a", "b"},
+		},
+		{
+			name: "overwritten value",
+			change: func(t *testing.T, s Store[tagged]) {
+				add(t, s, "a", "x")
+				add(t, s, "a", "y")
+			},
+			value: "x",
+		},
+		{
+			name: "new value of overwritten item",
+			change: func(t *testing.T, s Store[tagged]) {
+				add(t, s, "a", "x")
+				add(t, s, "a", "y")
+			},
+			value: "y",
+			want:  []string{"a"},
+		},
+		{
+			name: "removed item",
+			change: func(t *testing.T, s Store[tagged]) {
+				add(t, s, "a", "x")
+				add(t, s, "b", "x")
+
+				if err := s.Remove("a"); err != nil {
+					t.Fatalf("Remove() error = %v", err)
+				}
+			},
+			value: "x",
+			want:  []string{"b"},
+		},
+		{
+			// Expired items are removed by the owner of the store.
+			name: "expired item",
+			change: func(t *testing.T, s Store[tagged]) {
+				add(t, s, "a", "x")
+				add(t, s, "b", "x")
+
+				if err := s.SetExpiry("a", time.Now().Add(-time.Minute)); err != nil {
+					t.Fatalf("SetExpiry() error = %v", err)
+				}
+
+				for key := range s.Expiring(time.Now()) {
+					if err := s.Remove(key); err != nil {
+						t.Fatalf("Remove() error = %v", err)
+					}
+				}
+			},
+			value: "x",
+			want:  []string{"b"},
+		},
+		{
+			name: "missing value",
+			change: func(t *testing.T, s Store[tagged]) {
+				add(t, s, "a", "x")
+			},
+			value: "z",
+		},
+	}
+
+	for backend, open := range backends() {
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				s := open(t)
+
+				tt.change(t, s)
+
+				got := slices.Sorted(maps.Keys(maps.Collect(s.Find("tag", tt.value))))
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("Find(%q) = %v, want %v", tt.value, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestStoreFindUnknownIndex(t *testing.T) {
+	for backend, open := range backends() {
+		t.Run(backend, func(t *testing.T) {
+			s := open(t)
+
+			add(t, s, "a", "x")
+
+			if got := maps.Collect(s.Find("color", "x")); len(got) != 0 {
+				t.Errorf("Find() = %v, want none", got)
+			}
+		})
+	}
+}
+
+func add(t *testing.T, s Store[tagged], key string, tags ...string) {
+	t.Helper()
+
+	if err := s.Add(key, tagged{Tags: tags}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+}
+
+func TestStorageBatch(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "store.json")
+
+	s, err := Open[tagged](name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Batch(func() error {
+		add(t, s, "a", "x")
+		add(t, s, "b", "y")
+
+		// The file is written once after the batch.
+		if data, err := os.ReadFile(name); err != nil || len(data) != 0 {
+			t.Errorf("file = %q, %v during the batch, want it empty", data, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open[tagged](name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if got := slices.Sorted(maps.Keys(maps.Collect(s.All()))); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("keys = %v after reopening, want [a b]", got)
+	}
+}
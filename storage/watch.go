@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	EventAdd EventType = iota
+	EventUpdate
+	EventRemove
+)
+
+type (
+	EventType int
+
+	// Event is a change of an item in a Store.
+	Event[T any] struct {
+		Type EventType
+		Key  string
+		// Item is the new item, the zero value for EventRemove.
+		Item T
+		// Old is the replaced or removed item, the zero value for EventAdd.
+		Old T
+	}
+
+	// watchers delivers events to the watchers of a store in the order of
+	// the changes without blocking the writer.
+	watchers[T any] struct {
+		list   map[*watcher[T]]struct{}
+		listMu sync.Mutex
+	}
+
+	watcher[T any] struct {
+		queue   []Event[T]
+		queueMu sync.Mutex
+		ready   chan struct{}
+	}
+)
+
+// watch returns a channel receiving the events until the context is done.
+func (w *watchers[T]) watch(ctx context.Context) <-chan Event[T] {
+	var (
+		ch = make(chan Event[T])
+		wt = &watcher[T]{ready: make(chan struct{}, 1)}
+	)
+
+	w.listMu.Lock()
+
+	if w.list == nil {
+		w.list = make(map[*watcher[T]]struct{})
+	}
+
+	w.list[wt] = struct{}{}
+	w.listMu.Unlock()
+
+	go func() {
+		defer close(ch)
+
+		defer func() {
+			w.listMu.Lock()
+			delete(w.list, wt)
+			w.listMu.Unlock()
+		}()
+
+		for {
+			wt.queueMu.Lock()
+			queue := wt.queue
+			wt.queue = nil
+			wt.queueMu.Unlock()
+
+			for _, e := range queue {
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- e:
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-wt.ready:
+			}
+		}
+	}()
+
+	return ch
+}
+
+// emit queues the event for every watcher. Writers call it while holding
+// their lock, so the events are ordered like the changes.
+func (w *watchers[T]) emit(e Event[T]) {
+	w.listMu.Lock()
+	defer w.listMu.Unlock()
+
+	for wt := range w.list {
+		wt.queueMu.Lock()
+		wt.queue = append(wt.queue, e)
+		wt.queueMu.Unlock()
+
+		select {
+		case wt.ready <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdd:
+		return "add"
+	case EventUpdate:
+		return "update"
+	case EventRemove:
+		return "remove"
+	default:
+		return "unknown"
+	}
+}
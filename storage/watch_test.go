@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStoreWatch(t *testing.T) {
+	for backend, open := range backends() {
+		t.Run(backend, func(t *testing.T) {
+			s := open(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events := s.Watch(ctx)
+
+			add(t, s, "a", "x")
+			add(t, s, "a", "y")
+			add(t, s, "b", "x")
+
+			if err := s.Remove("a"); err != nil {
+				t.Fatalf("Remove() error = %v", err)
+			}
+
+			// Removing a missing item changes nothing.
+			if err := s.Remove("a"); err != nil {
+				t.Fatalf("Remove() error = %v", err)
+			}
+
+			if err := s.SetExpiry("b", time.Now().Add(-time.Minute)); err != nil {
+				t.Fatalf("SetExpiry() error = %v", err)
+			}
+
+			for key := range s.Expiring(time.Now()) {
+				if err := s.Remove(key); err != nil {
+					t.Fatalf("Remove() error = %v", err)
+				}
+			}
+
+			want := []string{"add a [] [x]", "update a [x] [y]", "add b [] [x]", "remove a [y] []", "remove b [x] []"}
+
+			var got []string
+
+			for range want {
+				select {
+				case e := <-events:
+					got = append(got, fmt.Sprintf("%s %s %v %v", e.Type, e.Key, e.Old.Tags, e.Item.Tags))
+				case <-time.After(time.Second):
+					t.Fatalf("events = %v, want %v", got, want)
+				}
+			}
+
+			if !slices.Equal(got, want) {
+				t.Errorf("events = %v, want %v", got, want)
+			}
+
+			select {
+			case e := <-events:
+				t.Errorf("unexpected event %+v", e)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestStoreWatchCancelled(t *testing.T) {
+	for backend, open := range backends() {
+		t.Run(backend, func(t *testing.T) {
+			s := open(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			events := s.Watch(ctx)
+
+			add(t, s, "a", "x")
+			cancel()
+
+			// Pending events may be dropped, but the channel is closed.
+			timeout := time.After(time.Second)
+
+			for {
+				select {
+				case _, ok := <-events:
+					if !ok {
+						return
+					}
+				case <-timeout:
+					t.Fatal("the channel wasn't closed")
+				}
+			}
+		})
+	}
+}
+
+func TestStoreWatchSlowWatcher(t *testing.T) {
+	const writes = 200
+
+	for backend, open := range backends() {
+		t.Run(backend, func(t *testing.T) {
+			s := open(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// The watcher doesn't read until all writes are done.
+			events := s.Watch(ctx)
+			written := make(chan struct{})
+
+			go func() {
+				defer close(written)
+
+				for i := range writes {
+					if err := s.Add(fmt.Sprint(i), tagged{}); err != nil {
+						t.Errorf("Add() error = %v", err)
+						return
+					}
+				}
+			}()
+
+			select {
+			case <-written:
+			case <-time.After(10 * time.Second):
+				t.Fatal("writes blocked on the watcher")
+			}
+
+			for i := range writes {
+				select {
+				case e := <-events:
+					if e.Key != fmt.Sprint(i) {
+						t.Fatalf("event %d is of %q, want in the order of the writes", i, e.Key)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("got %d events, want %d", i, writes)
+				}
+			}
+		})
+	}
+}